@@ -4,6 +4,23 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// AuthSwitchConfirmAnnotation confirms an auth type switch that removes users. Its value must
+// match status.pendingTransition.confirmationToken, so a confirmation only applies to the
+// users that were previewed.
+const AuthSwitchConfirmAnnotation = "frkr.io/confirm-auth-switch"
+
+// GatewayAuthConfigMapName is the ConfigMap the operator renders the effective auth
+// configuration into, in the namespace of the FrkrAuthConfig
+const GatewayAuthConfigMapName = "frkr-gateway-auth"
+
+// GatewayAuthConfigKey is the key of the rendered configuration in the gateway ConfigMap
+const GatewayAuthConfigKey = "auth.json"
+
+// AuthConfigHashAnnotation holds the hash of the rendered auth configuration. It is set on
+// the gateway ConfigMap and on the pod template of the gateway Deployments, so a change
+// rolls the gateways.
+const AuthConfigHashAnnotation = "frkr.io/auth-config-hash"
+
 // AuthType defines the authentication type
 // +kubebuilder:validation:Enum=basic;oidc;composite
 type AuthType string
@@ -14,14 +31,82 @@ const (
 	AuthTypeComposite AuthType = "composite"
 )
 
+// AuthProviderType is an authentication method that can be combined in composite mode
+// +kubebuilder:validation:Enum=basic;oidc;clientCredentials
+type AuthProviderType string
+
+const (
+	// AuthProviderBasic authenticates FrkrUsers with username and password
+	AuthProviderBasic AuthProviderType = "basic"
+	// AuthProviderOIDC authenticates with ID tokens of the provider in spec.oidcConfig
+	AuthProviderOIDC AuthProviderType = "oidc"
+	// AuthProviderClientCredentials authenticates FrkrClients with client ID and secret or certificate
+	AuthProviderClientCredentials AuthProviderType = "clientCredentials"
+)
+
+// AuthProvider is one authentication method of a composite auth config
+type AuthProvider struct {
+	// Type is the authentication method
+	Type AuthProviderType `json:"type"`
+
+	// Optional: Enabled turns the provider off without removing it from the list
+	// +optional
+	// +kubebuilder:default=true
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
 // FrkrAuthConfigSpec defines the desired state of FrkrAuthConfig
+// +kubebuilder:validation:XValidation:rule="self.type == 'composite' ? has(self.providers) && size(self.providers) > 0 : !has(self.providers)",message="providers is required for type composite and not allowed otherwise"
 type FrkrAuthConfigSpec struct {
-	// Type is the authentication type (basic or oidc)
+	// Type is the authentication type: basic, oidc, or composite (several providers in order)
 	Type AuthType `json:"type"`
 
-	// OIDCConfig is the OIDC configuration (required if type is oidc)
+	// Optional: Providers are the authentication methods of a composite config, in order of
+	// precedence: gateways try them first to last and use the first that accepts the request
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Providers []AuthProvider `json:"providers,omitempty"`
+
+	// OIDCConfig is the OIDC configuration (required if type is oidc, or composite with an oidc provider)
 	// +optional
 	OIDCConfig *OIDCConfig `json:"oidcConfig,omitempty"`
+
+	// PasswordPolicy constrains generated and user-supplied passwords of FrkrUsers in this namespace
+	// +optional
+	PasswordPolicy *PasswordPolicy `json:"passwordPolicy,omitempty"`
+}
+
+// CharacterClass is a class of characters a password can be required to contain
+// +kubebuilder:validation:Enum=upper;lower;digit;symbol
+type CharacterClass string
+
+const (
+	CharacterClassUpper  CharacterClass = "upper"
+	CharacterClassLower  CharacterClass = "lower"
+	CharacterClassDigit  CharacterClass = "digit"
+	CharacterClassSymbol CharacterClass = "symbol"
+)
+
+// PasswordPolicy defines password requirements
+type PasswordPolicy struct {
+	// MinLength is the minimum password length
+	// +optional
+	// +kubebuilder:validation:Minimum=8
+	MinLength int `json:"minLength,omitempty"`
+
+	// RequiredClasses are the character classes every password must contain
+	// +optional
+	RequiredClasses []CharacterClass `json:"requiredClasses,omitempty"`
+
+	// DisallowedWords are case-insensitive words passwords must not contain
+	// +optional
+	DisallowedWords []string `json:"disallowedWords,omitempty"`
+
+	// HistoryDepth is the number of previous passwords that cannot be reused
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	HistoryDepth int `json:"historyDepth,omitempty"`
 }
 
 // OIDCConfig defines OIDC provider configuration
@@ -35,9 +120,112 @@ type OIDCConfig struct {
 	// ClientSecret is the OIDC client secret (stored in secret)
 	ClientSecretRef string `json:"clientSecretRef"`
 
+	// Optional: ClientSecretKey is the key holding the client secret in the ClientSecretRef Secret
+	// +optional
+	// +kubebuilder:default=clientSecret
+	ClientSecretKey string `json:"clientSecretKey,omitempty"`
+
 	// Scopes are the OIDC scopes to request
 	// +optional
 	Scopes []string `json:"scopes,omitempty"`
+
+	// Optional: Audiences are the accepted token audiences (defaults to the client ID)
+	// +optional
+	Audiences []string `json:"audiences,omitempty"`
+
+	// Optional: ValidationInterval is how often the provider configuration is re-validated
+	// +optional
+	// +kubebuilder:default="1h"
+	ValidationInterval *metav1.Duration `json:"validationInterval,omitempty"`
+
+	// Optional: ClaimMappings assign tenants and roles to OIDC users from their token claims.
+	// The gateways apply them at login, so OIDC users need no FrkrUser.
+	// +optional
+	ClaimMappings *ClaimMappings `json:"claimMappings,omitempty"`
+}
+
+// ClaimMappings map the claims of an ID token to a frkr tenant and roles.
+// Expressions are RE2 regular expressions matched against the whole value; their capture
+// groups can be referenced as $1 or ${name} in the tenant or role they produce.
+type ClaimMappings struct {
+	// Optional: GroupsClaim is the claim listing the user's groups
+	// +optional
+	// +kubebuilder:default=groups
+	GroupsClaim string `json:"groupsClaim,omitempty"`
+
+	// Tenant rules are evaluated in order; the first rule that matches sets the tenant.
+	// Logins matching no rule are rejected.
+	// +optional
+	// +kubebuilder:validation:MaxItems=64
+	Tenant []TenantMapping `json:"tenant,omitempty"`
+
+	// Roles rules are all evaluated; the user is granted every role a rule produces
+	// +optional
+	// +kubebuilder:validation:MaxItems=256
+	Roles []RoleMapping `json:"roles,omitempty"`
+}
+
+// TenantMapping derives the tenant of a user from a claim
+type TenantMapping struct {
+	// Claim is the claim to read; dots separate nested objects (e.g. org.id)
+	// +kubebuilder:validation:MinLength=1
+	Claim string `json:"claim"`
+
+	// Optional: Match is the expression the claim value must match (defaults to any value)
+	// +optional
+	Match string `json:"match,omitempty"`
+
+	// Optional: TenantID is the tenant to assign, with capture group references
+	// (defaults to the claim value)
+	// +optional
+	TenantID string `json:"tenantId,omitempty"`
+}
+
+// RoleMapping grants a FrkrRole to users in matching groups
+type RoleMapping struct {
+	// Group is the expression a group of the user must match
+	// +kubebuilder:validation:MinLength=1
+	Group string `json:"group"`
+
+	// Role is the FrkrRole to grant, with capture group references
+	// +kubebuilder:validation:MinLength=1
+	Role string `json:"role"`
+
+	// Optional: TenantID limits the rule to users mapped to this tenant
+	// +optional
+	TenantID string `json:"tenantId,omitempty"`
+}
+
+// AuthTransition is an auth type switch waiting for confirmation because it removes users
+type AuthTransition struct {
+	// From is the auth type in effect
+	From AuthType `json:"from"`
+
+	// To is the requested auth type
+	To AuthType `json:"to"`
+
+	// Users are the FrkrUsers in this namespace that the switch deletes
+	Users []string `json:"users"`
+
+	// ConfirmationToken must be set as the frkr.io/confirm-auth-switch annotation to proceed
+	ConfirmationToken string `json:"confirmationToken"`
+}
+
+// GatewayRolloutStatus is the rollout of the auth configuration to a gateway Deployment
+type GatewayRolloutStatus struct {
+	// Name is the name of the gateway Deployment
+	Name string `json:"name"`
+
+	// State is Progressing, Complete or NotFound
+	State string `json:"state"`
+
+	// UpdatedReplicas is the number of replicas running the current configuration
+	// +optional
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+
+	// Replicas is the desired number of replicas
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
 }
 
 // FrkrAuthConfigStatus defines the observed state of FrkrAuthConfig
@@ -50,6 +238,31 @@ type FrkrAuthConfigStatus struct {
 	// +optional
 	PreviousType AuthType `json:"previousType,omitempty"`
 
+	// PendingTransition previews a switch that removes users until it is confirmed
+	// +optional
+	PendingTransition *AuthTransition `json:"pendingTransition,omitempty"`
+
+	// UserBackupSecret is the Secret holding the FrkrUsers removed by the last switch, for rollback
+	// +optional
+	UserBackupSecret string `json:"userBackupSecret,omitempty"`
+
+	// Providers are the enabled authentication methods in order of precedence
+	// +optional
+	Providers []AuthProviderType `json:"providers,omitempty"`
+
+	// ConfigHash is the hash of the auth configuration rendered for the gateways
+	// +optional
+	ConfigHash string `json:"configHash,omitempty"`
+
+	// Gateways reports the rollout of the configuration to the Deployments listed in the
+	// namespace's FrkrInit spec.gateways
+	// +optional
+	Gateways []GatewayRolloutStatus `json:"gateways,omitempty"`
+
+	// LastOIDCValidation is when the OIDC provider configuration was last validated
+	// +optional
+	LastOIDCValidation *metav1.Time `json:"lastOIDCValidation,omitempty"`
+
 	// Conditions represent the latest available observations
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
@@ -81,3 +294,26 @@ type FrkrAuthConfigList struct {
 func init() {
 	SchemeBuilder.Register(&FrkrAuthConfig{}, &FrkrAuthConfigList{})
 }
+
+// IsEnabled reports whether the provider is enabled (the default)
+func (p AuthProvider) IsEnabled() bool {
+	return p.Enabled == nil || *p.Enabled
+}
+
+// EnabledProviders returns the authentication methods in effect, in order of precedence.
+// Basic and oidc configs have a single provider; composite configs list theirs.
+func (s *FrkrAuthConfigSpec) EnabledProviders() []AuthProviderType {
+	switch s.Type {
+	case AuthTypeBasic:
+		return []AuthProviderType{AuthProviderBasic}
+	case AuthTypeOIDC:
+		return []AuthProviderType{AuthProviderOIDC}
+	}
+	var providers []AuthProviderType
+	for _, p := range s.Providers {
+		if p.IsEnabled() {
+			providers = append(providers, p.Type)
+		}
+	}
+	return providers
+}
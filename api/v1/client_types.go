@@ -4,7 +4,136 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// ClientRotateSecretAnnotation requests a client secret rotation when set to a new value
+// (frkrctl client rotate sets it to the current time)
+const ClientRotateSecretAnnotation = "frkr.io/rotate-secret"
+
+// ClientCredentialType selects how a client authenticates
+// +kubebuilder:validation:Enum=secret;mtls
+type ClientCredentialType string
+
+const (
+	// ClientCredentialSecret authenticates with a client ID and shared secret
+	ClientCredentialSecret ClientCredentialType = "secret"
+	// ClientCredentialMTLS authenticates with a certificate issued by the operator's internal CA
+	ClientCredentialMTLS ClientCredentialType = "mtls"
+)
+
+// ClientCertificateSpec configures the certificates issued to mtls clients
+type ClientCertificateSpec struct {
+	// Optional: Duration is how long an issued certificate is valid
+	// +optional
+	// +kubebuilder:default="2160h"
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// Optional: RenewBefore is how long before expiry the certificate is renewed
+	// +optional
+	// +kubebuilder:default="720h"
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
+// ClientCertificateStatus describes the certificate currently issued to an mtls client
+type ClientCertificateStatus struct {
+	// Subject is the certificate subject (CN=<clientId>, O=<tenantId>)
+	Subject string `json:"subject"`
+
+	// Fingerprint is the hex encoded SHA-256 fingerprint registered in the database
+	Fingerprint string `json:"fingerprint"`
+
+	// SerialNumber is the certificate serial number
+	SerialNumber string `json:"serialNumber"`
+
+	// NotBefore is when the certificate becomes valid
+	NotBefore metav1.Time `json:"notBefore"`
+
+	// NotAfter is when the certificate expires
+	NotAfter metav1.Time `json:"notAfter"`
+
+	// RenewAt is when the operator renews the certificate
+	RenewAt metav1.Time `json:"renewAt"`
+}
+
+// StreamPermission is an action a client may perform on a stream
+// +kubebuilder:validation:Enum=read;write
+type StreamPermission string
+
+const (
+	StreamPermissionRead  StreamPermission = "read"
+	StreamPermissionWrite StreamPermission = "write"
+)
+
+// ClientScope grants a client permissions on a single stream
+type ClientScope struct {
+	// StreamRef is the name of a FrkrStream in the client's namespace
+	StreamRef string `json:"streamRef"`
+
+	// Permissions are the actions allowed on the stream
+	// +kubebuilder:validation:MinItems=1
+	Permissions []StreamPermission `json:"permissions"`
+}
+
+// ClientScopeStatus is a scope resolved to its stream
+type ClientScopeStatus struct {
+	// StreamRef is the name of the referenced FrkrStream
+	StreamRef string `json:"streamRef"`
+
+	// StreamID is the database ID of the stream
+	StreamID string `json:"streamId"`
+
+	// Permissions are the actions allowed on the stream
+	Permissions []StreamPermission `json:"permissions"`
+}
+
+// ClientRateLimit caps the request rate of a client
+type ClientRateLimit struct {
+	// RequestsPerSecond is the sustained number of requests allowed per second
+	// +kubebuilder:validation:Minimum=1
+	RequestsPerSecond int32 `json:"requestsPerSecond"`
+
+	// Optional: Burst is the number of requests allowed above the sustained rate
+	// (defaults to requestsPerSecond)
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Burst int32 `json:"burst,omitempty"`
+}
+
+// SecretDelivery copies the client credentials into a Secret in another namespace.
+// The namespace must be allowed by the tenant's FrkrTenant spec.allowedDeliveryNamespaces.
+type SecretDelivery struct {
+	// Namespace is the namespace to create the Secret in
+	Namespace string `json:"namespace"`
+
+	// Optional: SecretName is the name of the delivered Secret (defaults to frkr-client-<name>)
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// Optional: Keys renames credential keys (clientId, clientSecret, previousClientSecret) in
+	// the delivered Secret; keys that are not listed keep their name
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self.all(k, k in ['clientId', 'clientSecret', 'previousClientSecret'])",message="keys can only rename clientId, clientSecret and previousClientSecret"
+	Keys map[string]string `json:"keys,omitempty"`
+}
+
+// SecretDeliveryStatus reports the state of a single delivery
+type SecretDeliveryStatus struct {
+	// Namespace is the namespace of the delivered Secret
+	Namespace string `json:"namespace"`
+
+	// SecretName is the name of the delivered Secret
+	SecretName string `json:"secretName"`
+
+	// Delivered is true when the Secret is in sync with the client credentials
+	Delivered bool `json:"delivered"`
+
+	// Message explains why the Secret was not delivered
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
 // FrkrClientSpec defines the desired state of FrkrClient
+// +kubebuilder:validation:XValidation:rule="!(has(self.credentialType) && self.credentialType == 'mtls' && (has(self.secret) || has(self.secretRef)))",message="secret and secretRef cannot be used with credentialType mtls"
+// +kubebuilder:validation:XValidation:rule="[has(self.streamId), has(self.streamRef), has(self.scopes)].filter(x, x).size() <= 1",message="streamId, streamRef and scopes are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="(has(self.credentialType) ? self.credentialType : 'secret') == (has(oldSelf.credentialType) ? oldSelf.credentialType : 'secret')",message="credentialType is immutable"
 type FrkrClientSpec struct {
 	// TenantID is the UUID of the tenant
 	TenantID string `json:"tenantId"`
@@ -12,14 +141,77 @@ type FrkrClientSpec struct {
 	// ClientID is the desired client ID string
 	ClientID string `json:"clientId"`
 
-	// Optional: StreamID to scope this client to
+	// Optional: CredentialType is how the client authenticates: a shared secret (default) or a
+	// client certificate issued by the operator (mtls)
+	// +optional
+	// +kubebuilder:default=secret
+	CredentialType ClientCredentialType `json:"credentialType,omitempty"`
+
+	// Optional: Certificate configures the issued certificate when credentialType is mtls
+	// +optional
+	Certificate *ClientCertificateSpec `json:"certificate,omitempty"`
+
+	// Optional: StreamID is the database UUID of the stream to scope this client to.
+	// Prefer StreamRef, which is resolved by the operator.
 	// +optional
 	StreamID string `json:"streamId,omitempty"`
 
+	// Optional: StreamRef is the name of a FrkrStream in the client's namespace to scope this client to
+	// +optional
+	StreamRef string `json:"streamRef,omitempty"`
+
+	// Optional: Scopes limits the client to the listed streams with per-stream permissions
+	// +optional
+	Scopes []ClientScope `json:"scopes,omitempty"`
+
 	// Optional: Secret is the client credentials secret
 	// If empty, one will be generated
+	// Deprecated: inline secrets are stored in plaintext; use SecretRef instead
 	// +optional
 	Secret string `json:"secret,omitempty"`
+
+	// Optional: SecretRef reads the client secret from a Secret (key defaults to "clientSecret").
+	// Takes precedence over Secret; changes to the Secret are re-synced to the database.
+	// +optional
+	SecretRef *SecretKeyReference `json:"secretRef,omitempty"`
+
+	// Optional: RotationGracePeriod is how long the previous secret stays valid after a rotation
+	// +optional
+	// +kubebuilder:default="24h"
+	RotationGracePeriod *metav1.Duration `json:"rotationGracePeriod,omitempty"`
+
+	// Optional: ExpiresAt is when the credential stops being accepted
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// Optional: TTL expires the credential this long after the FrkrClient was created.
+	// If ExpiresAt is also set, the earlier of the two applies.
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+
+	// Optional: ExpiryWarning is how long before expiry the ExpiringSoon condition is raised
+	// +optional
+	// +kubebuilder:default="72h"
+	ExpiryWarning *metav1.Duration `json:"expiryWarning,omitempty"`
+
+	// Optional: DeleteOnExpiry deletes the FrkrClient once the credential has expired
+	// +optional
+	DeleteOnExpiry bool `json:"deleteOnExpiry,omitempty"`
+
+	// Optional: AllowedCIDRs limits the source addresses the credential is accepted from
+	// (CIDR blocks or single IP addresses); empty allows any address
+	// +optional
+	// +kubebuilder:validation:MaxItems=64
+	AllowedCIDRs []string `json:"allowedCIDRs,omitempty"`
+
+	// Optional: RateLimit caps the request rate of the credential
+	// +optional
+	RateLimit *ClientRateLimit `json:"rateLimit,omitempty"`
+
+	// Optional: DeliverTo replicates the credentials into Secrets in consumer namespaces.
+	// Delivered Secrets are kept in sync and removed when the FrkrClient is deleted.
+	// +optional
+	DeliverTo []SecretDelivery `json:"deliverTo,omitempty"`
 }
 
 // FrkrClientStatus defines the observed state of FrkrClient
@@ -33,6 +225,46 @@ type FrkrClientStatus struct {
 	// SecretGenerated indicates if the secret was auto-generated
 	SecretGenerated bool `json:"secretGenerated,omitempty"`
 
+	// LastRotation is when the secret was last rotated
+	// +optional
+	LastRotation *metav1.Time `json:"lastRotation,omitempty"`
+
+	// ObservedRotationRequest is the last frkr.io/rotate-secret annotation value that was applied
+	// +optional
+	ObservedRotationRequest string `json:"observedRotationRequest,omitempty"`
+
+	// PreviousSecretExpiresAt is when the previous secret stops being accepted
+	// +optional
+	PreviousSecretExpiresAt *metav1.Time `json:"previousSecretExpiresAt,omitempty"`
+
+	// ExpiresAt is when the credential stops being accepted, resolved from spec.expiresAt and spec.ttl
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// StreamID is the database ID of the stream resolved from spec.streamRef
+	// +optional
+	StreamID string `json:"streamId,omitempty"`
+
+	// Scopes are the spec.scopes entries whose streams could be resolved
+	// +optional
+	Scopes []ClientScopeStatus `json:"scopes,omitempty"`
+
+	// AllowedCIDRs are the normalized source address blocks enforced by the gateways
+	// +optional
+	AllowedCIDRs []string `json:"allowedCIDRs,omitempty"`
+
+	// RateLimit is the rate limit enforced by the gateways, with the burst defaulted
+	// +optional
+	RateLimit *ClientRateLimit `json:"rateLimit,omitempty"`
+
+	// Certificate describes the certificate issued when credentialType is mtls
+	// +optional
+	Certificate *ClientCertificateStatus `json:"certificate,omitempty"`
+
+	// Deliveries reports the Secrets replicated by spec.deliverTo
+	// +optional
+	Deliveries []SecretDeliveryStatus `json:"deliveries,omitempty"`
+
 	// Conditions store the status conditions
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
@@ -41,7 +273,9 @@ type FrkrClientStatus struct {
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="ID",type=string,JSONPath=`.status.id`
 //+kubebuilder:printcolumn:name="ClientID",type=string,JSONPath=`.spec.clientId`
+//+kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.credentialType`
 //+kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Expires",type=string,JSONPath=`.status.expiresAt`
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // FrkrClient is the Schema for the frkrclients API
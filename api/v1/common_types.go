@@ -0,0 +1,12 @@
+package v1
+
+// SecretKeyReference selects a key of a Secret in the same namespace
+type SecretKeyReference struct {
+	// Name is the name of the Secret
+	Name string `json:"name"`
+
+	// Key is the key within the Secret's data
+	// If empty, the referencing field documents the default key
+	// +optional
+	Key string `json:"key,omitempty"`
+}
@@ -0,0 +1,67 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Permission is an action a role allows
+// +kubebuilder:validation:Enum=stream:read;stream:write;stream:admin;tenant:read;tenant:admin
+type Permission string
+
+const (
+	PermissionStreamRead  Permission = "stream:read"
+	PermissionStreamWrite Permission = "stream:write"
+	PermissionStreamAdmin Permission = "stream:admin"
+	PermissionTenantRead  Permission = "tenant:read"
+	PermissionTenantAdmin Permission = "tenant:admin"
+)
+
+// IsStreamScoped reports whether the permission can be limited to specific streams
+func (p Permission) IsStreamScoped() bool {
+	switch p {
+	case PermissionStreamRead, PermissionStreamWrite, PermissionStreamAdmin:
+		return true
+	}
+	return false
+}
+
+// FrkrRoleSpec defines the desired state of FrkrRole
+type FrkrRoleSpec struct {
+	// TenantID is the tenant/organization ID this role is scoped to
+	TenantID string `json:"tenantId"`
+
+	// Permissions are the actions granted by this role
+	// +kubebuilder:validation:MinItems=1
+	Permissions []Permission `json:"permissions"`
+
+	// Streams limits stream permissions to the named streams
+	// If empty, stream permissions apply to all streams of the tenant
+	// +optional
+	Streams []string `json:"streams,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:printcolumn:name="Tenant",type="string",JSONPath=".spec.tenantId"
+//+kubebuilder:printcolumn:name="Permissions",type="string",JSONPath=".spec.permissions"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// FrkrRole is the Schema for the frkrroles API
+type FrkrRole struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec FrkrRoleSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// FrkrRoleList contains a list of FrkrRole
+type FrkrRoleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FrkrRole `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FrkrRole{}, &FrkrRoleList{})
+}
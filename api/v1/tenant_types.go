@@ -13,6 +13,18 @@ type FrkrTenantSpec struct {
 	// Plan is the subscription plan (default: free)
 	// +optional
 	Plan string `json:"plan,omitempty"`
+
+	// AllowedDeliveryNamespaces lists the namespaces (glob patterns such as "team-a-*") that
+	// FrkrClients of this tenant may replicate credentials into with spec.deliverTo. It only
+	// applies to clients in a namespace bound to the tenant (see clientNamespaces).
+	// +optional
+	AllowedDeliveryNamespaces []string `json:"allowedDeliveryNamespaces,omitempty"`
+
+	// ClientNamespaces lists the namespaces (glob patterns) whose FrkrClients are bound to
+	// this tenant for credential delivery, besides the tenant's own namespace. A client's
+	// spec.tenantId alone does not grant it the tenant's allowedDeliveryNamespaces.
+	// +optional
+	ClientNamespaces []string `json:"clientNamespaces,omitempty"`
 }
 
 // FrkrTenantStatus defines the observed state of FrkrTenant
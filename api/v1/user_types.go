@@ -4,21 +4,80 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// UserPurgeRetainedAnnotation, set to "true", lets a FrkrUser replace a database record
+// retained by an earlier deletion with the Retain policy. The retained record, including its
+// password history, is deleted first.
+const UserPurgeRetainedAnnotation = "frkr.io/purge-retained-user"
+
+// UserDeletionPolicy defines what happens to the database user when a FrkrUser is deleted
+// +kubebuilder:validation:Enum=Delete;Retain
+type UserDeletionPolicy string
+
+const (
+	// UserDeletionPolicyDelete removes the database user
+	UserDeletionPolicyDelete UserDeletionPolicy = "Delete"
+	// UserDeletionPolicyRetain keeps the database record for audit but disables authentication
+	UserDeletionPolicyRetain UserDeletionPolicy = "Retain"
+)
+
 // FrkrUserSpec defines the desired state of FrkrUser
 type FrkrUserSpec struct {
 	// Username is the user's username
 	Username string `json:"username"`
 
 	// Password is optional. If not provided, a random password will be generated
+	// Deprecated: inline passwords are stored in plaintext; use PasswordSecretRef instead
 	// +optional
 	Password string `json:"password,omitempty"`
 
+	// PasswordSecretRef reads the password from a Secret (key defaults to "password").
+	// Takes precedence over Password; changes to the Secret are re-synced to the database.
+	// +optional
+	PasswordSecretRef *SecretKeyReference `json:"passwordSecretRef,omitempty"`
+
 	// TenantID is the tenant/organization ID this user belongs to
 	TenantID string `json:"tenantId"`
 
-	// Roles are the user's roles
+	// Roles are the names of FrkrRoles in the user's namespace granted to the user
 	// +optional
 	Roles []string `json:"roles,omitempty"`
+
+	// PasswordResetGeneration requests a password reset when incremented.
+	// A reset is pending while it is greater than status.observedPasswordResetGeneration;
+	// the operator then generates a new password (or applies Password if set).
+	// +optional
+	PasswordResetGeneration int64 `json:"passwordResetGeneration,omitempty"`
+
+	// Rotation configures automatic password rotation
+	// +optional
+	Rotation *PasswordRotation `json:"rotation,omitempty"`
+
+	// DeletionPolicy controls whether the database user is removed or retained (disabled) on deletion
+	// +optional
+	// +kubebuilder:default=Delete
+	DeletionPolicy UserDeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// Disabled revokes the user's access without deleting it
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// LockedUntil temporarily revokes the user's access until the given time
+	// +optional
+	LockedUntil *metav1.Time `json:"lockedUntil,omitempty"`
+
+	// LockReason explains why the user is disabled or locked
+	// +optional
+	LockReason string `json:"lockReason,omitempty"`
+}
+
+// PasswordRotation defines an automatic password rotation policy
+type PasswordRotation struct {
+	// MaxAge is the maximum age of a password before it is rotated (e.g. "2160h" for 90 days)
+	MaxAge metav1.Duration `json:"maxAge"`
+
+	// NotifyBefore is how long before the rotation deadline the RotationDue condition is raised
+	// +optional
+	NotifyBefore *metav1.Duration `json:"notifyBefore,omitempty"`
 }
 
 // FrkrUserStatus defines the observed state of FrkrUser
@@ -28,7 +87,8 @@ type FrkrUserStatus struct {
 	Phase string `json:"phase,omitempty"`
 
 	// Password is the generated or provided password (one-time display only)
-	// This field is populated only once when the user is created
+	// This field is populated when the user is created and whenever the password is reset.
+	// It stays empty for passwords read from spec.passwordSecretRef.
 	// +optional
 	Password string `json:"password,omitempty"`
 
@@ -40,6 +100,31 @@ type FrkrUserStatus struct {
 	// +optional
 	LastPasswordReset *metav1.Time `json:"lastPasswordReset,omitempty"`
 
+	// ObservedPasswordResetGeneration is the last spec.passwordResetGeneration that was applied
+	// +optional
+	ObservedPasswordResetGeneration int64 `json:"observedPasswordResetGeneration,omitempty"`
+
+	// CredentialsSecret is the name of the Secret holding the user's credentials
+	// +optional
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+
+	// LockReason is the reason the user is currently disabled or locked
+	// +optional
+	LockReason string `json:"lockReason,omitempty"`
+
+	// LockedUntil is when the current lock expires
+	// +optional
+	LockedUntil *metav1.Time `json:"lockedUntil,omitempty"`
+
+	// EffectivePermissions is the permission set granted by the user's resolved roles.
+	// Stream-scoped grants are shown as <permission>:<stream>
+	// +optional
+	EffectivePermissions []string `json:"effectivePermissions,omitempty"`
+
+	// AppliedRoles are the names of the roles whose grants are persisted for the user
+	// +optional
+	AppliedRoles []string `json:"appliedRoles,omitempty"`
+
 	// Conditions represent the latest available observations of the user's state
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
+	"github.com/frkr-io/frkr-operator/internal/claims"
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Inspect the auth configuration",
+	Long:  `Inspect and test the FrkrAuthConfig of the namespace.`,
+}
+
+var authTestClaimsCmd = &cobra.Command{
+	Use:   "test-claims [token.json|-]",
+	Short: "Evaluate the OIDC claim mappings against a sample ID token",
+	Long: `Evaluate the claim mappings against the claims of a sample ID token, as the gateways do at login.
+
+The token is a JSON object of claims, or a compact JWT whose payload is decoded without
+verifying its signature. Read it from a file, or from stdin with "-".
+
+The mappings are read from the FrkrAuthConfig in the namespace, or with --mappings from a
+local FrkrAuthConfig manifest or claimMappings document, without contacting the cluster.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mappingsFile, _ := cmd.Flags().GetString("mappings")
+		authConfigName, _ := cmd.Flags().GetString("auth-config")
+
+		tokenClaims, err := readTokenClaims(args[0])
+		if err != nil {
+			return err
+		}
+
+		var mappings *frkrv1.ClaimMappings
+		if mappingsFile != "" {
+			mappings, err = readClaimMappingsFile(mappingsFile)
+		} else {
+			mappings, err = clusterClaimMappings(context.Background(), authConfigName)
+		}
+		if err != nil {
+			return err
+		}
+
+		mapper, err := claims.Compile(mappings)
+		if err != nil {
+			return fmt.Errorf("invalid claim mappings:\n%w", err)
+		}
+		result := mapper.Evaluate(tokenClaims)
+
+		if structuredOutput() {
+			return writeStructured(result, []envVar{
+				{"FRKR_TENANT_ID", result.TenantID},
+				{"FRKR_ROLES", strings.Join(result.RoleNames(), ",")},
+			})
+		}
+
+		if len(result.Groups) > 0 {
+			fmt.Printf("Groups: %s\n", strings.Join(result.Groups, ", "))
+		} else {
+			fmt.Println("Groups: none")
+		}
+		if result.TenantID == "" {
+			fmt.Println("❌ No tenant rule matched; the gateways reject this login")
+		} else {
+			fmt.Printf("✅ Tenant: %s (tenant[%d])\n", result.TenantID, result.TenantRule)
+			if len(result.Roles) == 0 {
+				fmt.Println("Roles: none")
+			} else {
+				fmt.Println("Roles:")
+				for _, g := range result.Roles {
+					fmt.Printf("  - %s (group %q, roles[%d])\n", g.Role, g.Group, g.Rule)
+				}
+			}
+		}
+		for _, s := range result.Skipped {
+			fmt.Printf("⚠️  Skipped %s\n", s)
+		}
+		return nil
+	},
+}
+
+// readTokenClaims reads the claims of a sample ID token from a file or stdin
+func readTokenClaims(path string) (map[string]any, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token: %w", err)
+	}
+
+	// A compact JWT is header.payload.signature; only the payload is used
+	token := strings.TrimSpace(string(data))
+	if parts := strings.Split(token, "."); len(parts) == 3 && !strings.HasPrefix(token, "{") {
+		payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode token payload: %w", err)
+		}
+		data = payload
+	}
+
+	var tokenClaims map[string]any
+	if err := json.Unmarshal(data, &tokenClaims); err != nil {
+		return nil, fmt.Errorf("failed to parse token claims: %w", err)
+	}
+	return tokenClaims, nil
+}
+
+// readClaimMappingsFile reads claim mappings from a FrkrAuthConfig manifest or a bare
+// claimMappings document
+func readClaimMappingsFile(path string) (*frkrv1.ClaimMappings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var typeMeta struct {
+		Kind string `json:"kind"`
+	}
+	if err := yaml.Unmarshal(data, &typeMeta); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if typeMeta.Kind == "" {
+		var mappings frkrv1.ClaimMappings
+		if err := yaml.UnmarshalStrict(data, &mappings); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		return &mappings, nil
+	}
+
+	var authConfig frkrv1.FrkrAuthConfig
+	if err := yaml.Unmarshal(data, &authConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if authConfig.Kind != "FrkrAuthConfig" {
+		return nil, fmt.Errorf("%s is a %s, expected a FrkrAuthConfig or claimMappings document", path, authConfig.Kind)
+	}
+	return authConfigClaimMappings(&authConfig)
+}
+
+// clusterClaimMappings reads the claim mappings of a FrkrAuthConfig in the namespace; with
+// no name, the namespace must have exactly one
+func clusterClaimMappings(ctx context.Context, name string) (*frkrv1.ClaimMappings, error) {
+	k8sClient, err := getK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	ns, err := getNamespace()
+	if err != nil {
+		return nil, err
+	}
+
+	var authConfig frkrv1.FrkrAuthConfig
+	if name != "" {
+		if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: ns}, &authConfig); err != nil {
+			return nil, fmt.Errorf("failed to get auth config %s: %w", name, err)
+		}
+		return authConfigClaimMappings(&authConfig)
+	}
+
+	var list frkrv1.FrkrAuthConfigList
+	if err := k8sClient.List(ctx, &list, client.InNamespace(ns)); err != nil {
+		return nil, fmt.Errorf("failed to list auth configs: %w", err)
+	}
+	switch len(list.Items) {
+	case 0:
+		return nil, fmt.Errorf("no FrkrAuthConfig in namespace %s (use --mappings to test a local file)", ns)
+	case 1:
+		return authConfigClaimMappings(&list.Items[0])
+	default:
+		return nil, fmt.Errorf("namespace %s has %d auth configs; select one with --auth-config", ns, len(list.Items))
+	}
+}
+
+// authConfigClaimMappings returns the claim mappings of an auth config
+func authConfigClaimMappings(authConfig *frkrv1.FrkrAuthConfig) (*frkrv1.ClaimMappings, error) {
+	if authConfig.Spec.OIDCConfig == nil || authConfig.Spec.OIDCConfig.ClaimMappings == nil {
+		return nil, fmt.Errorf("auth config %s has no spec.oidcConfig.claimMappings", authConfig.Name)
+	}
+	return authConfig.Spec.OIDCConfig.ClaimMappings, nil
+}
+
+func init() {
+	authTestClaimsCmd.Flags().String("mappings", "", "Read the claim mappings from a local FrkrAuthConfig manifest or claimMappings file")
+	authTestClaimsCmd.Flags().String("auth-config", "", "FrkrAuthConfig to read the claim mappings from (defaults to the only one in the namespace)")
+	authTestClaimsCmd.MarkFlagsMutuallyExclusive("mappings", "auth-config")
+	authCmd.AddCommand(authTestClaimsCmd)
+	rootCmd.AddCommand(authCmd)
+}
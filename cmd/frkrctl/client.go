@@ -3,10 +3,12 @@ package main
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -16,7 +18,9 @@ import (
 var clientCmd = &cobra.Command{
 	Use:   "client",
 	Short: "Manage client credentials via operator",
-	Long:  `Create and list client credentials via Kubernetes CRDs.`,
+	Long: `Create, list, describe, rotate, and delete client credentials via Kubernetes CRDs.
+
+describe, credentials, rotate and delete support -o json|yaml|env for scripting.`,
 }
 
 var clientCreateCmd = &cobra.Command{
@@ -28,11 +32,67 @@ var clientCreateCmd = &cobra.Command{
 		clientID := args[0]
 		tenantID, _ := cmd.Flags().GetString("tenant-id")
 		streamID, _ := cmd.Flags().GetString("stream-id")
+		streamRef, _ := cmd.Flags().GetString("stream")
 		secret, _ := cmd.Flags().GetString("secret")
+		secretRef, _ := cmd.Flags().GetString("secret-ref")
+		ttl, _ := cmd.Flags().GetDuration("ttl")
+		expiresAt, _ := cmd.Flags().GetString("expires-at")
+		deleteOnExpiry, _ := cmd.Flags().GetBool("delete-on-expiry")
+		scopeFlags, _ := cmd.Flags().GetStringArray("scope")
+		deliverTo, _ := cmd.Flags().GetStringArray("deliver-to")
+		credentialType, _ := cmd.Flags().GetString("credential-type")
+		allowedCIDRs, _ := cmd.Flags().GetStringArray("allowed-cidr")
+		rateLimit, _ := cmd.Flags().GetInt32("rate-limit")
+		rateLimitBurst, _ := cmd.Flags().GetInt32("rate-limit-burst")
 
 		if tenantID == "" {
 			return fmt.Errorf("--tenant-id is required")
 		}
+		if err := validateExportFlags(cmd); err != nil {
+			return err
+		}
+		exporting := exportRequested(cmd)
+		streamFlags := 0
+		for _, set := range []bool{streamID != "", streamRef != "", len(scopeFlags) > 0} {
+			if set {
+				streamFlags++
+			}
+		}
+		if streamFlags > 1 {
+			return fmt.Errorf("--stream, --stream-id and --scope are mutually exclusive")
+		}
+		scopes, err := parseClientScopes(scopeFlags)
+		if err != nil {
+			return err
+		}
+		if secret != "" && secretRef != "" {
+			return fmt.Errorf("--secret and --secret-ref are mutually exclusive")
+		}
+		mtls := frkrv1.ClientCredentialType(credentialType) == frkrv1.ClientCredentialMTLS
+		switch frkrv1.ClientCredentialType(credentialType) {
+		case frkrv1.ClientCredentialSecret:
+		case frkrv1.ClientCredentialMTLS:
+			if secret != "" || secretRef != "" {
+				return fmt.Errorf("--secret and --secret-ref cannot be used with --credential-type mtls")
+			}
+		default:
+			return fmt.Errorf("invalid --credential-type %q (expected secret or mtls)", credentialType)
+		}
+		if rateLimit < 0 || rateLimitBurst < 0 {
+			return fmt.Errorf("--rate-limit and --rate-limit-burst must not be negative")
+		}
+		if rateLimitBurst > 0 && rateLimit == 0 {
+			return fmt.Errorf("--rate-limit-burst requires --rate-limit")
+		}
+		var expiry *metav1.Time
+		if expiresAt != "" {
+			parsed, err := time.Parse(time.RFC3339, expiresAt)
+			if err != nil {
+				return fmt.Errorf("invalid --expires-at (expected RFC3339): %w", err)
+			}
+			t := metav1.NewTime(parsed)
+			expiry = &t
+		}
 
 		// Get k8s client
 		k8sClient, err := getK8sClient()
@@ -54,19 +114,41 @@ var clientCreateCmd = &cobra.Command{
 				Namespace: ns,
 			},
 			Spec: frkrv1.FrkrClientSpec{
-				TenantID: tenantID,
-				ClientID: clientID,
-				StreamID: streamID,
-				Secret:   secret,
+				TenantID:       tenantID,
+				ClientID:       clientID,
+				CredentialType: frkrv1.ClientCredentialType(credentialType),
+				StreamID:       streamID,
+				StreamRef:      streamRef,
+				Secret:         secret,
+				Scopes:         scopes,
 			},
 		}
+		if secretRef != "" {
+			name, key, _ := strings.Cut(secretRef, "/")
+			crd.Spec.SecretRef = &frkrv1.SecretKeyReference{Name: name, Key: key}
+		}
+		crd.Spec.ExpiresAt = expiry
+		if ttl > 0 {
+			crd.Spec.TTL = &metav1.Duration{Duration: ttl}
+		}
+		crd.Spec.DeleteOnExpiry = deleteOnExpiry
+		crd.Spec.AllowedCIDRs = allowedCIDRs
+		if rateLimit > 0 {
+			crd.Spec.RateLimit = &frkrv1.ClientRateLimit{RequestsPerSecond: rateLimit, Burst: rateLimitBurst}
+		}
+		for _, target := range deliverTo {
+			namespace, name, _ := strings.Cut(target, "/")
+			crd.Spec.DeliverTo = append(crd.Spec.DeliverTo, frkrv1.SecretDelivery{Namespace: namespace, SecretName: name})
+		}
 
 		if err := k8sClient.Create(context.Background(), crd); err != nil {
 			return fmt.Errorf("failed to create client CRD: %w", err)
 		}
 
-		fmt.Printf("✅ Client request submitted for '%s'\n", clientID)
-		fmt.Println("Waiting for secret generation...")
+		if !exporting {
+			fmt.Printf("✅ Client request submitted for '%s'\n", clientID)
+			fmt.Println("Waiting for secret generation...")
+		}
 
 		// Poll for Secret
 		timeout := time.After(30 * time.Second)
@@ -78,7 +160,10 @@ var clientCreateCmd = &cobra.Command{
 		for {
 			select {
 			case <-timeout:
-				fmt.Printf("⚠️  Timed out waiting for secret. Check status with: kubectl get frkrclient %s -o yaml\n", crdName)
+				if exporting {
+					return fmt.Errorf("timed out waiting for secret; check status with: frkrctl client describe %s", clientID)
+				}
+				fmt.Printf("⚠️  Timed out waiting for secret. Check status with: frkrctl client describe %s\n", clientID)
 				return nil
 			case <-ticker.C:
 				var secret corev1.Secret
@@ -86,12 +171,23 @@ var clientCreateCmd = &cobra.Command{
 					Name:      secretName,
 					Namespace: ns,
 				}, &secret); err == nil {
+					ready := len(secret.Data["clientSecret"]) > 0 || (mtls && len(secret.Data[corev1.TLSCertKey]) > 0)
+					if exporting && ready {
+						return writeExport(context.Background(), cmd, k8sClient, clientExport(crd, &secret))
+					}
+					if mtls && len(secret.Data[corev1.TLSCertKey]) > 0 {
+						fmt.Printf("\n✅ Client Certificate Ready!\n")
+						fmt.Printf("ClientID: %s\n", clientID)
+						fmt.Printf("The certificate, key and CA are stored in Secret %s/%s (tls.crt, tls.key, ca.crt).\n", ns, secretName)
+						return nil
+					}
 					clientSecret := string(secret.Data["clientSecret"])
 					if clientSecret != "" {
 						fmt.Printf("\n✅ Client Credential Ready!\n")
 						fmt.Printf("ClientID:     %s\n", clientID)
 						fmt.Printf("ClientSecret: %s\n", clientSecret)
 						fmt.Println("\nSave this secret! It is stored in a Kubernetes Secret but displayed here for convenience.")
+						fmt.Printf("Show it again with: frkrctl client credentials %s\n", clientID)
 						return nil
 					}
 				}
@@ -100,6 +196,28 @@ var clientCreateCmd = &cobra.Command{
 	},
 }
 
+// parseClientScopes parses --scope values of the form <stream>=<permission>[,<permission>...]
+func parseClientScopes(values []string) ([]frkrv1.ClientScope, error) {
+	var scopes []frkrv1.ClientScope
+	for _, value := range values {
+		streamRef, perms, ok := strings.Cut(value, "=")
+		if !ok || streamRef == "" || perms == "" {
+			return nil, fmt.Errorf("invalid --scope %q (expected <stream>=read,write)", value)
+		}
+		scope := frkrv1.ClientScope{StreamRef: streamRef}
+		for _, perm := range strings.Split(perms, ",") {
+			switch p := frkrv1.StreamPermission(strings.TrimSpace(perm)); p {
+			case frkrv1.StreamPermissionRead, frkrv1.StreamPermissionWrite:
+				scope.Permissions = append(scope.Permissions, p)
+			default:
+				return nil, fmt.Errorf("invalid permission %q in --scope %q (expected read or write)", perm, value)
+			}
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes, nil
+}
+
 var clientListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List client credentials",
@@ -132,13 +250,596 @@ var clientListCmd = &cobra.Command{
 	},
 }
 
+var clientRotateCmd = &cobra.Command{
+	Use:   "rotate [client-id]",
+	Short: "Rotate a client secret",
+	Long: `Rotate a generated client secret (sets the frkr.io/rotate-secret annotation on the FrkrClient CRD).
+
+The previous secret stays valid for the client's rotation grace period and is
+available as previousClientSecret in the client's Kubernetes Secret until then.
+For mtls clients a new client certificate is issued instead.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clientID := args[0]
+		tenantID, _ := cmd.Flags().GetString("tenant-id")
+		timeoutSeconds, _ := cmd.Flags().GetInt("timeout")
+
+		k8sClient, err := getK8sClient()
+		if err != nil {
+			return err
+		}
+
+		ns, err := getNamespace()
+		if err != nil {
+			return err
+		}
+
+		crd, err := findClient(context.Background(), k8sClient, ns, clientID, tenantID)
+		if err != nil {
+			return err
+		}
+		if err := validateExportFlags(cmd); err != nil {
+			return err
+		}
+		exporting := exportRequested(cmd)
+
+		request := time.Now().UTC().Format(time.RFC3339Nano)
+		if crd.Annotations == nil {
+			crd.Annotations = map[string]string{}
+		}
+		crd.Annotations[frkrv1.ClientRotateSecretAnnotation] = request
+		if err := k8sClient.Update(context.Background(), crd); err != nil {
+			return fmt.Errorf("failed to request rotation: %w", err)
+		}
+
+		if !structuredOutput() && !exporting {
+			fmt.Printf("✅ Rotation requested for client %s\n", clientID)
+			fmt.Println("Waiting for the new secret...")
+		}
+
+		// Poll until the operator has applied the rotation
+		timeout := time.After(time.Duration(timeoutSeconds) * time.Second)
+		ticker := time.NewTicker(1 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-timeout:
+				return fmt.Errorf("timed out waiting for rotation (%ds); check status with: kubectl get frkrclient %s -o yaml", timeoutSeconds, crd.Name)
+			case <-ticker.C:
+				var updated frkrv1.FrkrClient
+				if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(crd), &updated); err != nil {
+					continue
+				}
+				if updated.Status.ObservedRotationRequest != request {
+					continue
+				}
+				mtls := updated.Spec.CredentialType == frkrv1.ClientCredentialMTLS
+				if mtls && updated.Status.Certificate == nil {
+					continue
+				}
+				if !mtls && updated.Status.PreviousSecretExpiresAt == nil {
+					return fmt.Errorf("secret of client %s was not rotated; it is supplied by spec.secret or spec.secretRef", clientID)
+				}
+				if exporting {
+					var secret corev1.Secret
+					if err := k8sClient.Get(context.Background(), client.ObjectKey{
+						Name:      fmt.Sprintf("frkr-client-%s", updated.Name),
+						Namespace: ns,
+					}, &secret); err != nil {
+						continue
+					}
+					return writeExport(context.Background(), cmd, k8sClient, clientExport(&updated, &secret))
+				}
+				if mtls {
+					cert := updated.Status.Certificate
+					notAfter := formatTime(&cert.NotAfter)
+					if structuredOutput() {
+						return writeStructured(map[string]string{
+							"client_id":   clientID,
+							"fingerprint": cert.Fingerprint,
+							"not_after":   notAfter,
+						}, []envVar{
+							{"FRKR_CLIENT_ID", clientID},
+							{"FRKR_CLIENT_CERT_FINGERPRINT", cert.Fingerprint},
+							{"FRKR_CLIENT_CERT_NOT_AFTER", notAfter},
+						})
+					}
+					fmt.Printf("\n✅ Certificate renewed (fingerprint %s, valid until %s)\n", cert.Fingerprint, notAfter)
+					return nil
+				}
+
+				var secret corev1.Secret
+				if err := k8sClient.Get(context.Background(), client.ObjectKey{
+					Name:      fmt.Sprintf("frkr-client-%s", updated.Name),
+					Namespace: ns,
+				}, &secret); err != nil {
+					continue
+				}
+				expiresAt := updated.Status.PreviousSecretExpiresAt.UTC().Format(time.RFC3339)
+
+				if structuredOutput() {
+					return writeStructured(map[string]string{
+						"client_id":                  clientID,
+						"client_secret":              string(secret.Data["clientSecret"]),
+						"previous_secret_expires_at": expiresAt,
+					}, []envVar{
+						{"FRKR_CLIENT_ID", clientID},
+						{"FRKR_CLIENT_SECRET", string(secret.Data["clientSecret"])},
+						{"FRKR_PREVIOUS_SECRET_EXPIRES_AT", expiresAt},
+					})
+				}
+				fmt.Printf("\nClientSecret: %s\n", string(secret.Data["clientSecret"]))
+				fmt.Printf("The previous secret stays valid until %s\n", expiresAt)
+				return nil
+			}
+		}
+	},
+}
+
+var clientDeleteCmd = &cobra.Command{
+	Use:   "delete [client-id]",
+	Short: "Delete a client credential",
+	Long: `Delete a client credential (deletes FrkrClient CRD).
+
+The operator revokes the credential in the database and removes its consumer
+group from the broker before the CRD is released. The command waits until the
+revocation is complete.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clientID := args[0]
+		tenantID, _ := cmd.Flags().GetString("tenant-id")
+		timeoutSeconds, _ := cmd.Flags().GetInt("timeout")
+
+		k8sClient, err := getK8sClient()
+		if err != nil {
+			return err
+		}
+
+		ns, err := getNamespace()
+		if err != nil {
+			return err
+		}
+
+		crd, err := findClient(context.Background(), k8sClient, ns, clientID, tenantID)
+		if err != nil {
+			return err
+		}
+
+		if err := k8sClient.Delete(context.Background(), crd); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete client: %w", err)
+		}
+		if !structuredOutput() {
+			fmt.Printf("✅ Deletion requested for client %s\n", clientID)
+			fmt.Println("Waiting for the credential to be revoked...")
+		}
+
+		// The CRD is released once the operator's finalizer has revoked the credential
+		timeout := time.After(time.Duration(timeoutSeconds) * time.Second)
+		ticker := time.NewTicker(1 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-timeout:
+				return fmt.Errorf("timed out waiting for revocation (%ds); check events with: kubectl describe frkrclient %s", timeoutSeconds, crd.Name)
+			case <-ticker.C:
+				var updated frkrv1.FrkrClient
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(crd), &updated)
+				if apierrors.IsNotFound(err) {
+					if structuredOutput() {
+						return writeStructured(map[string]any{
+							"client_id": clientID,
+							"tenant_id": crd.Spec.TenantID,
+							"revoked":   true,
+						}, []envVar{
+							{"FRKR_CLIENT_ID", clientID},
+							{"FRKR_TENANT_ID", crd.Spec.TenantID},
+							{"FRKR_CLIENT_REVOKED", "true"},
+						})
+					}
+					fmt.Printf("✅ Client %s revoked and deleted\n", clientID)
+					return nil
+				}
+			}
+		}
+	},
+}
+
+// clientScopeDescription is a stream scope in the describe output
+type clientScopeDescription struct {
+	StreamRef   string   `json:"stream_ref"`
+	StreamID    string   `json:"stream_id,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+// conditionDescription is a status condition in the describe output
+type conditionDescription struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// rateLimitDescription is the enforced rate limit in the describe output
+type rateLimitDescription struct {
+	RequestsPerSecond int32 `json:"requests_per_second"`
+	Burst             int32 `json:"burst"`
+}
+
+// certificateDescription is the client certificate of an mtls client in the describe output
+type certificateDescription struct {
+	Subject     string `json:"subject"`
+	Fingerprint string `json:"fingerprint"`
+	NotAfter    string `json:"not_after"`
+	RenewAt     string `json:"renew_at"`
+}
+
+// clientDescription is the describe output of a client credential
+type clientDescription struct {
+	Name                    string                   `json:"name"`
+	ClientID                string                   `json:"client_id"`
+	TenantID                string                   `json:"tenant_id"`
+	ID                      string                   `json:"id,omitempty"`
+	Phase                   string                   `json:"phase,omitempty"`
+	CredentialType          string                   `json:"credential_type"`
+	StreamRef               string                   `json:"stream_ref,omitempty"`
+	StreamID                string                   `json:"stream_id,omitempty"`
+	Scopes                  []clientScopeDescription `json:"scopes,omitempty"`
+	CreatedAt               string                   `json:"created_at,omitempty"`
+	LastRotation            string                   `json:"last_rotation,omitempty"`
+	PreviousSecretExpiresAt string                   `json:"previous_secret_expires_at,omitempty"`
+	ExpiresAt               string                   `json:"expires_at,omitempty"`
+	AllowedCIDRs            []string                 `json:"allowed_cidrs,omitempty"`
+	RateLimit               *rateLimitDescription    `json:"rate_limit,omitempty"`
+	Certificate             *certificateDescription  `json:"certificate,omitempty"`
+	Conditions              []conditionDescription   `json:"conditions,omitempty"`
+}
+
+func describeClient(crd *frkrv1.FrkrClient) clientDescription {
+	d := clientDescription{
+		Name:                    crd.Name,
+		ClientID:                crd.Spec.ClientID,
+		TenantID:                crd.Spec.TenantID,
+		ID:                      crd.Status.ID,
+		Phase:                   crd.Status.Phase,
+		CredentialType:          orDefault(string(crd.Spec.CredentialType), string(frkrv1.ClientCredentialSecret)),
+		StreamRef:               crd.Spec.StreamRef,
+		StreamID:                crd.Status.StreamID,
+		CreatedAt:               formatTime(&crd.CreationTimestamp),
+		LastRotation:            formatTime(crd.Status.LastRotation),
+		PreviousSecretExpiresAt: formatTime(crd.Status.PreviousSecretExpiresAt),
+		ExpiresAt:               formatTime(crd.Status.ExpiresAt),
+		AllowedCIDRs:            crd.Status.AllowedCIDRs,
+	}
+	if d.StreamID == "" {
+		d.StreamID = crd.Spec.StreamID
+	}
+	if limit := crd.Status.RateLimit; limit != nil {
+		d.RateLimit = &rateLimitDescription{RequestsPerSecond: limit.RequestsPerSecond, Burst: limit.Burst}
+	}
+	if cert := crd.Status.Certificate; cert != nil {
+		d.Certificate = &certificateDescription{
+			Subject:     cert.Subject,
+			Fingerprint: cert.Fingerprint,
+			NotAfter:    formatTime(&cert.NotAfter),
+			RenewAt:     formatTime(&cert.RenewAt),
+		}
+	}
+
+	resolved := map[string]string{}
+	for _, scope := range crd.Status.Scopes {
+		resolved[scope.StreamRef] = scope.StreamID
+	}
+	for _, scope := range crd.Spec.Scopes {
+		perms := make([]string, 0, len(scope.Permissions))
+		for _, perm := range scope.Permissions {
+			perms = append(perms, string(perm))
+		}
+		d.Scopes = append(d.Scopes, clientScopeDescription{
+			StreamRef:   scope.StreamRef,
+			StreamID:    resolved[scope.StreamRef],
+			Permissions: perms,
+		})
+	}
+
+	for _, cond := range crd.Status.Conditions {
+		d.Conditions = append(d.Conditions, conditionDescription{
+			Type:    cond.Type,
+			Status:  string(cond.Status),
+			Reason:  cond.Reason,
+			Message: cond.Message,
+		})
+	}
+	return d
+}
+
+// formatTime formats an optional timestamp as RFC3339, or "" if unset
+func formatTime(t *metav1.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// orDefault returns value, or fallback if value is empty
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+var clientDescribeCmd = &cobra.Command{
+	Use:   "describe [client-id]",
+	Short: "Show details of a client credential",
+	Long:  `Show the scope, status, creation, rotation and expiry times of a client credential (reads FrkrClient CRD).`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clientID := args[0]
+		tenantID, _ := cmd.Flags().GetString("tenant-id")
+
+		k8sClient, err := getK8sClient()
+		if err != nil {
+			return err
+		}
+
+		ns, err := getNamespace()
+		if err != nil {
+			return err
+		}
+
+		crd, err := findClient(context.Background(), k8sClient, ns, clientID, tenantID)
+		if err != nil {
+			return err
+		}
+		d := describeClient(crd)
+
+		if structuredOutput() {
+			return writeStructured(d, []envVar{
+				{"FRKR_CLIENT_NAME", d.Name},
+				{"FRKR_CLIENT_ID", d.ClientID},
+				{"FRKR_TENANT_ID", d.TenantID},
+				{"FRKR_CLIENT_UUID", d.ID},
+				{"FRKR_CLIENT_PHASE", d.Phase},
+				{"FRKR_CLIENT_CREDENTIAL_TYPE", d.CredentialType},
+				{"FRKR_STREAM_ID", d.StreamID},
+				{"FRKR_CLIENT_CREATED_AT", d.CreatedAt},
+				{"FRKR_CLIENT_LAST_ROTATION", d.LastRotation},
+				{"FRKR_CLIENT_EXPIRES_AT", d.ExpiresAt},
+			})
+		}
+
+		fmt.Printf("Name:          %s\n", d.Name)
+		fmt.Printf("Client ID:     %s\n", d.ClientID)
+		fmt.Printf("Tenant ID:     %s\n", d.TenantID)
+		fmt.Printf("ID:            %s\n", orDefault(d.ID, "<pending>"))
+		fmt.Printf("Status:        %s\n", orDefault(d.Phase, "<pending>"))
+		fmt.Printf("Credential:    %s\n", d.CredentialType)
+		switch {
+		case len(d.Scopes) > 0:
+			fmt.Println("Scopes:")
+			for _, scope := range d.Scopes {
+				fmt.Printf("  %-20s %-36s %s\n", scope.StreamRef, orDefault(scope.StreamID, "<unresolved>"), strings.Join(scope.Permissions, ","))
+			}
+		case d.StreamRef != "":
+			fmt.Printf("Stream:        %s (%s)\n", d.StreamRef, orDefault(d.StreamID, "<unresolved>"))
+		case d.StreamID != "":
+			fmt.Printf("Stream:        %s\n", d.StreamID)
+		default:
+			fmt.Println("Stream:        <all streams>")
+		}
+		fmt.Printf("Created:       %s\n", d.CreatedAt)
+		fmt.Printf("Last rotation: %s\n", orDefault(d.LastRotation, "<never>"))
+		if d.PreviousSecretExpiresAt != "" {
+			fmt.Printf("Previous secret valid until: %s\n", d.PreviousSecretExpiresAt)
+		}
+		fmt.Printf("Expires:       %s\n", orDefault(d.ExpiresAt, "<never>"))
+		if len(d.AllowedCIDRs) > 0 {
+			fmt.Printf("Allowed from:  %s\n", strings.Join(d.AllowedCIDRs, ", "))
+		}
+		if d.RateLimit != nil {
+			fmt.Printf("Rate limit:    %d req/s (burst %d)\n", d.RateLimit.RequestsPerSecond, d.RateLimit.Burst)
+		}
+		if d.Certificate != nil {
+			fmt.Println("Certificate:")
+			fmt.Printf("  Subject:     %s\n", d.Certificate.Subject)
+			fmt.Printf("  Fingerprint: %s\n", d.Certificate.Fingerprint)
+			fmt.Printf("  Valid until: %s (renews at %s)\n", d.Certificate.NotAfter, d.Certificate.RenewAt)
+		}
+		if len(d.Conditions) > 0 {
+			fmt.Println("Conditions:")
+			for _, cond := range d.Conditions {
+				fmt.Printf("  %s=%s (%s): %s\n", cond.Type, cond.Status, cond.Reason, cond.Message)
+			}
+		}
+		return nil
+	},
+}
+
+var clientCredentialsCmd = &cobra.Command{
+	Use:   "credentials [client-id]",
+	Short: "Show the credentials of a client",
+	Long: `Show the client ID and secret of a client again (reads the client's Kubernetes Secret).
+
+Use -o env to load them into a shell: eval "$(frkrctl client credentials my-app -o env)"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clientID := args[0]
+		tenantID, _ := cmd.Flags().GetString("tenant-id")
+
+		k8sClient, err := getK8sClient()
+		if err != nil {
+			return err
+		}
+
+		ns, err := getNamespace()
+		if err != nil {
+			return err
+		}
+
+		crd, err := findClient(context.Background(), k8sClient, ns, clientID, tenantID)
+		if err != nil {
+			return err
+		}
+
+		var secret corev1.Secret
+		if err := k8sClient.Get(context.Background(), client.ObjectKey{
+			Name:      fmt.Sprintf("frkr-client-%s", crd.Name),
+			Namespace: ns,
+		}, &secret); err != nil {
+			return fmt.Errorf("failed to read credentials of client %s (status: %s): %w", crd.Spec.ClientID, orDefault(crd.Status.Phase, "<pending>"), err)
+		}
+		if !metav1.IsControlledBy(&secret, crd) {
+			return fmt.Errorf("secret %s is not managed by client %s", secret.Name, crd.Spec.ClientID)
+		}
+
+		if exportRequested(cmd) {
+			if err := validateExportFlags(cmd); err != nil {
+				return err
+			}
+			return writeExport(context.Background(), cmd, k8sClient, clientExport(crd, &secret))
+		}
+
+		if crd.Spec.CredentialType == frkrv1.ClientCredentialMTLS {
+			if structuredOutput() {
+				return writeStructured(map[string]string{
+					"client_id": crd.Spec.ClientID,
+					"tenant_id": crd.Spec.TenantID,
+					"tls_crt":   string(secret.Data[corev1.TLSCertKey]),
+					"tls_key":   string(secret.Data[corev1.TLSPrivateKeyKey]),
+					"ca_crt":    string(secret.Data["ca.crt"]),
+				}, []envVar{
+					{"FRKR_CLIENT_ID", crd.Spec.ClientID},
+					{"FRKR_TENANT_ID", crd.Spec.TenantID},
+					{"FRKR_CLIENT_CERT", string(secret.Data[corev1.TLSCertKey])},
+					{"FRKR_CLIENT_KEY", string(secret.Data[corev1.TLSPrivateKeyKey])},
+					{"FRKR_CA_CERT", string(secret.Data["ca.crt"])},
+				})
+			}
+			fmt.Printf("ClientID: %s\n", crd.Spec.ClientID)
+			fmt.Printf("%s", secret.Data[corev1.TLSCertKey])
+			fmt.Printf("\nThe key and CA are stored in Secret %s/%s (tls.key, ca.crt); use -o json to print them.\n", ns, secret.Name)
+			return nil
+		}
+
+		clientSecret := string(secret.Data["clientSecret"])
+		previousSecret := string(secret.Data["previousClientSecret"])
+		previousExpiresAt := formatTime(crd.Status.PreviousSecretExpiresAt)
+
+		if structuredOutput() {
+			out := map[string]string{
+				"client_id":     crd.Spec.ClientID,
+				"tenant_id":     crd.Spec.TenantID,
+				"client_secret": clientSecret,
+			}
+			vars := []envVar{
+				{"FRKR_CLIENT_ID", crd.Spec.ClientID},
+				{"FRKR_TENANT_ID", crd.Spec.TenantID},
+				{"FRKR_CLIENT_SECRET", clientSecret},
+			}
+			if previousSecret != "" {
+				out["previous_client_secret"] = previousSecret
+				out["previous_secret_expires_at"] = previousExpiresAt
+				vars = append(vars, envVar{"FRKR_PREVIOUS_CLIENT_SECRET", previousSecret})
+			}
+			return writeStructured(out, vars)
+		}
+
+		fmt.Printf("ClientID:     %s\n", crd.Spec.ClientID)
+		fmt.Printf("ClientSecret: %s\n", clientSecret)
+		if previousSecret != "" {
+			fmt.Printf("\nThe previous secret %s stays valid until %s\n", previousSecret, previousExpiresAt)
+		}
+		return nil
+	},
+}
+
+// clientExport describes the credentials in a client's Secret for --format
+func clientExport(crd *frkrv1.FrkrClient, secret *corev1.Secret) credentialExport {
+	exp := credentialExport{
+		Name:      secret.Name,
+		Namespace: secret.Namespace,
+		Fields: []exportField{
+			{Key: "client_id", EnvVar: "FRKR_CLIENT_ID", SecretKey: "clientId", Value: crd.Spec.ClientID},
+			{Key: "tenant_id", EnvVar: "FRKR_TENANT_ID", SecretKey: "tenantId", Value: crd.Spec.TenantID},
+		},
+	}
+	if crd.Spec.CredentialType == frkrv1.ClientCredentialMTLS {
+		exp.SecretType = corev1.SecretTypeTLS
+		exp.Fields = append(exp.Fields,
+			exportField{Key: "tls_crt", EnvVar: "FRKR_CLIENT_CERT", SecretKey: corev1.TLSCertKey, Value: string(secret.Data[corev1.TLSCertKey])},
+			exportField{Key: "tls_key", EnvVar: "FRKR_CLIENT_KEY", SecretKey: corev1.TLSPrivateKeyKey, Value: string(secret.Data[corev1.TLSPrivateKeyKey])},
+			exportField{Key: "ca_crt", EnvVar: "FRKR_CA_CERT", SecretKey: "ca.crt", Value: string(secret.Data["ca.crt"])},
+		)
+		return exp
+	}
+	clientSecret := string(secret.Data["clientSecret"])
+	exp.Fields = append(exp.Fields, exportField{Key: "client_secret", EnvVar: "FRKR_CLIENT_SECRET", SecretKey: "clientSecret", Value: clientSecret})
+	exp.Login, exp.Password = crd.Spec.ClientID, clientSecret
+	return exp
+}
+
+// findClient returns the FrkrClient for a client ID (or FrkrClient name), optionally restricted to a tenant
+func findClient(ctx context.Context, k8sClient client.Client, ns, clientID, tenantID string) (*frkrv1.FrkrClient, error) {
+	var list frkrv1.FrkrClientList
+	if err := k8sClient.List(ctx, &list, client.InNamespace(ns)); err != nil {
+		return nil, fmt.Errorf("failed to list clients: %w", err)
+	}
+
+	var matches []*frkrv1.FrkrClient
+	for i := range list.Items {
+		c := &list.Items[i]
+		if (c.Spec.ClientID == clientID || c.Name == clientID) && (tenantID == "" || c.Spec.TenantID == tenantID) {
+			matches = append(matches, c)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("client %s not found", clientID)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("client %s exists in several tenants; use --tenant-id", clientID)
+	}
+}
+
 func init() {
 	clientCreateCmd.Flags().String("tenant-id", "", "Tenant ID (required)")
-	clientCreateCmd.Flags().String("stream-id", "", "Stream ID to scope to (optional)")
+	clientCreateCmd.Flags().String("stream", "", "FrkrStream name to scope to (optional)")
+	clientCreateCmd.Flags().String("stream-id", "", "Stream database ID to scope to (optional, prefer --stream)")
+	clientCreateCmd.Flags().StringArray("scope", nil, "Scope to a FrkrStream with permissions, e.g. orders=read or audit=read,write (repeatable)")
 	clientCreateCmd.Flags().String("secret", "", "Optional custom secret")
+	clientCreateCmd.Flags().String("secret-ref", "", "Read the secret from a Kubernetes Secret (name[/key], key defaults to clientSecret)")
+	clientCreateCmd.Flags().Duration("ttl", 0, "Expire the credential after this duration (e.g. 720h)")
+	clientCreateCmd.Flags().String("expires-at", "", "Expire the credential at an RFC3339 timestamp")
+	clientCreateCmd.Flags().Bool("delete-on-expiry", false, "Delete the client once the credential has expired")
+	clientCreateCmd.Flags().String("credential-type", string(frkrv1.ClientCredentialSecret), "Credential type: secret or mtls (client certificate issued by the operator's CA)")
+	clientCreateCmd.Flags().StringArray("allowed-cidr", nil, "Only accept the credential from this CIDR block or IP address (repeatable)")
+	clientCreateCmd.Flags().Int32("rate-limit", 0, "Limit the credential to this many requests per second")
+	clientCreateCmd.Flags().Int32("rate-limit-burst", 0, "Requests allowed above --rate-limit (defaults to --rate-limit)")
+	clientCreateCmd.Flags().StringArray("deliver-to", nil, "Replicate the credentials into another namespace (namespace[/secret-name], repeatable)")
+	_ = clientCreateCmd.Flags().MarkDeprecated("secret", "inline secrets are stored in plaintext; use --secret-ref")
+
+	clientRotateCmd.Flags().String("tenant-id", "", "Tenant ID (required if the client ID exists in several tenants)")
+	clientRotateCmd.Flags().Int("timeout", 90, "Timeout in seconds to wait for the new secret")
+	for _, cmd := range []*cobra.Command{clientCreateCmd, clientRotateCmd, clientCredentialsCmd} {
+		addExportFlags(cmd)
+	}
+
+	clientDescribeCmd.Flags().String("tenant-id", "", "Tenant ID (required if the client ID exists in several tenants)")
+	clientCredentialsCmd.Flags().String("tenant-id", "", "Tenant ID (required if the client ID exists in several tenants)")
+
+	clientDeleteCmd.Flags().String("tenant-id", "", "Tenant ID (required if the client ID exists in several tenants)")
+	clientDeleteCmd.Flags().Int("timeout", 90, "Timeout in seconds to wait for revocation")
 
 	clientCmd.AddCommand(clientCreateCmd)
 	clientCmd.AddCommand(clientListCmd)
+	clientCmd.AddCommand(clientRotateCmd)
+	clientCmd.AddCommand(clientDeleteCmd)
+	clientCmd.AddCommand(clientDescribeCmd)
+	clientCmd.AddCommand(clientCredentialsCmd)
 
 	rootCmd.AddCommand(clientCmd)
 }
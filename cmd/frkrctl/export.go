@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// exportFormats are the values accepted by --format
+var exportFormats = []string{"env", "dotenv", "k8s-secret", "json", "netrc"}
+
+// gatewayLabels select the Services and Ingresses of the frkr gateways
+var gatewayLabels = client.MatchingLabels{"app.kubernetes.io/part-of": "frkr"}
+
+// exportField is a single value of an exported credential
+type exportField struct {
+	// Key is the key in the json format
+	Key string
+	// EnvVar is the variable name in the env and dotenv formats
+	EnvVar string
+	// SecretKey is the key in the k8s-secret format
+	SecretKey string
+	Value     string
+}
+
+// credentialExport is a credential that can be written with --format
+type credentialExport struct {
+	// Name and Namespace of the Secret in the k8s-secret format
+	Name       string
+	Namespace  string
+	SecretType corev1.SecretType
+	Fields     []exportField
+
+	// Login and Password are written in the netrc format; empty if it is not supported
+	Login    string
+	Password string
+}
+
+// addExportFlags adds the credential export flags to a credential-producing command
+func addExportFlags(cmd *cobra.Command) {
+	cmd.Flags().String("format", "", fmt.Sprintf("Export the credentials as %s (defaults to dotenv with --out)", strings.Join(exportFormats, "|")))
+	cmd.Flags().String("out", "", "Write the exported credentials to this file (created with mode 0600)")
+	cmd.Flags().Bool("connection", false, "Include the gateway URL discovered from the cluster for a ready-to-use connection snippet")
+	cmd.Flags().String("gateway-url", "", "Gateway URL to use instead of discovering it (or set FRKR_GATEWAY_URL)")
+}
+
+// exportRequested reports whether --format or --out was given. Commands print no progress
+// output to stdout in that case, so the export can be piped.
+func exportRequested(cmd *cobra.Command) bool {
+	format, _ := cmd.Flags().GetString("format")
+	out, _ := cmd.Flags().GetString("out")
+	return format != "" || out != ""
+}
+
+// validateExportFlags checks --format before a command changes anything
+func validateExportFlags(cmd *cobra.Command) error {
+	format, _ := cmd.Flags().GetString("format")
+	if format != "" && !slices.Contains(exportFormats, format) {
+		return fmt.Errorf("invalid --format %q (expected %s)", format, strings.Join(exportFormats, ", "))
+	}
+	return nil
+}
+
+// writeExport renders the credential in the format selected by --format and writes it to
+// --out, or to stdout
+func writeExport(ctx context.Context, cmd *cobra.Command, k8sClient client.Client, exp credentialExport) error {
+	format, _ := cmd.Flags().GetString("format")
+	out, _ := cmd.Flags().GetString("out")
+	connection, _ := cmd.Flags().GetBool("connection")
+	gatewayOverride, _ := cmd.Flags().GetString("gateway-url")
+	if format == "" {
+		format = "dotenv"
+	}
+
+	var gatewayURL string
+	if connection || format == "netrc" {
+		var err error
+		gatewayURL, err = discoverGatewayURL(ctx, k8sClient, exp.Namespace, gatewayOverride)
+		if err != nil {
+			return err
+		}
+	}
+	if connection {
+		exp.Fields = append(exp.Fields, exportField{Key: "gateway_url", EnvVar: "FRKR_GATEWAY_URL", SecretKey: "gatewayUrl", Value: gatewayURL})
+	}
+
+	data, err := renderExport(format, exp, gatewayURL)
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := writePrivateFile(out, data); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Credentials written to %s (%s)\n", out, format)
+	return nil
+}
+
+// renderExport encodes a credential in one of the export formats
+func renderExport(format string, exp credentialExport, gatewayURL string) ([]byte, error) {
+	var buf bytes.Buffer
+	switch format {
+	case "env":
+		for _, f := range exp.Fields {
+			fmt.Fprintf(&buf, "export %s=%s\n", f.EnvVar, shellQuote(f.Value))
+		}
+	case "dotenv":
+		for _, f := range exp.Fields {
+			fmt.Fprintf(&buf, "%s=%s\n", f.EnvVar, dotenvQuote(f.Value))
+		}
+	case "json":
+		values := make(map[string]string, len(exp.Fields))
+		for _, f := range exp.Fields {
+			values[f.Key] = f.Value
+		}
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(values); err != nil {
+			return nil, fmt.Errorf("failed to encode json: %w", err)
+		}
+	case "k8s-secret":
+		secret := corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: metav1.ObjectMeta{Name: exp.Name, Namespace: exp.Namespace},
+			Type:       exp.SecretType,
+			StringData: map[string]string{},
+		}
+		for _, f := range exp.Fields {
+			secret.StringData[f.SecretKey] = f.Value
+		}
+		data, err := yaml.Marshal(secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode secret: %w", err)
+		}
+		buf.Write(data)
+	case "netrc":
+		if exp.Login == "" {
+			return nil, fmt.Errorf("the netrc format is not supported for these credentials")
+		}
+		host := gatewayURL
+		if u, err := url.Parse(gatewayURL); err == nil && u.Hostname() != "" {
+			host = u.Hostname()
+		}
+		fmt.Fprintf(&buf, "machine %s login %s password %s\n", host, exp.Login, exp.Password)
+	default:
+		return nil, fmt.Errorf("invalid --format %q (expected %s)", format, strings.Join(exportFormats, ", "))
+	}
+	return buf.Bytes(), nil
+}
+
+// dotenvQuote quotes a value for .env files: single quotes keep it literal, double quotes
+// with escapes are used for values containing single quotes or newlines
+func dotenvQuote(value string) string {
+	if !strings.ContainsAny(value, "'\n") {
+		return "'" + value + "'"
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(value) + `"`
+}
+
+// writePrivateFile writes data to path, readable by the owner only. The data goes to a
+// temporary file in the same directory that is renamed into place, so it is never readable
+// by others and an existing file is only replaced once the write succeeded.
+func writePrivateFile(path string, data []byte) error {
+	// CreateTemp creates the file with mode 0600
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %s: %w", path, err)
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// discoverGatewayURL returns the URL clients use to reach the frkr gateway: the override
+// or FRKR_GATEWAY_URL if set, else the host of a frkr Ingress, else the address of a frkr
+// LoadBalancer Service, else the in-cluster address of a frkr Service
+func discoverGatewayURL(ctx context.Context, k8sClient client.Client, ns, override string) (string, error) {
+	if override == "" {
+		override = os.Getenv("FRKR_GATEWAY_URL")
+	}
+	if override != "" {
+		return strings.TrimSuffix(override, "/"), nil
+	}
+
+	var ingresses networkingv1.IngressList
+	if err := k8sClient.List(ctx, &ingresses, client.InNamespace(ns), gatewayLabels); err != nil {
+		return "", fmt.Errorf("failed to list ingresses: %w", err)
+	}
+	for _, ing := range ingresses.Items {
+		for _, rule := range ing.Spec.Rules {
+			if rule.Host == "" {
+				continue
+			}
+			scheme := "http"
+			for _, tls := range ing.Spec.TLS {
+				if slices.Contains(tls.Hosts, rule.Host) {
+					scheme = "https"
+				}
+			}
+			return fmt.Sprintf("%s://%s", scheme, rule.Host), nil
+		}
+	}
+
+	var services corev1.ServiceList
+	if err := k8sClient.List(ctx, &services, client.InNamespace(ns), gatewayLabels); err != nil {
+		return "", fmt.Errorf("failed to list services: %w", err)
+	}
+	// Prefer Services that are named as gateways over other frkr Services
+	slices.SortStableFunc(services.Items, func(a, b corev1.Service) int {
+		return boolRank(strings.Contains(b.Name, "gateway")) - boolRank(strings.Contains(a.Name, "gateway"))
+	})
+	for _, svc := range services.Items {
+		port, ok := gatewayPort(&svc)
+		if !ok || svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			continue
+		}
+		for _, lb := range svc.Status.LoadBalancer.Ingress {
+			host := lb.Hostname
+			if host == "" {
+				host = lb.IP
+			}
+			if host != "" {
+				return serviceURL(host, port), nil
+			}
+		}
+	}
+	for _, svc := range services.Items {
+		if port, ok := gatewayPort(&svc); ok {
+			return serviceURL(fmt.Sprintf("%s.%s.svc", svc.Name, svc.Namespace), port), nil
+		}
+	}
+	return "", fmt.Errorf("no frkr gateway found in namespace %s (use --gateway-url or FRKR_GATEWAY_URL)", ns)
+}
+
+// gatewayPort returns the port a Service serves gateway traffic on, skipping metrics ports
+func gatewayPort(svc *corev1.Service) (int32, bool) {
+	for _, p := range svc.Spec.Ports {
+		if p.Name != "metrics" {
+			return p.Port, true
+		}
+	}
+	return 0, false
+}
+
+func serviceURL(host string, port int32) string {
+	switch port {
+	case 443:
+		return fmt.Sprintf("https://%s", host)
+	case 80:
+		return fmt.Sprintf("http://%s", host)
+	}
+	return fmt.Sprintf("http://%s:%d", host, port)
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
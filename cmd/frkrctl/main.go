@@ -15,7 +15,7 @@ var rootCmd = &cobra.Command{
 var outputFormat string
 
 func init() {
-	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text, json)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text, json; client and auth commands also support yaml, env)")
 }
 
 func main() {
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// envVar is a single line of -o env output
+type envVar struct {
+	Name  string
+	Value string
+}
+
+// structuredOutput reports whether -o selects a machine-readable format
+func structuredOutput() bool {
+	switch outputFormat {
+	case "json", "yaml", "env":
+		return true
+	}
+	return false
+}
+
+// writeStructured prints v as JSON or YAML, or vars as shell-quoted NAME=value lines,
+// depending on -o. Callers print their own text output when structuredOutput is false.
+func writeStructured(v any, vars []envVar) error {
+	switch outputFormat {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "yaml":
+		out, err := yaml.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to encode yaml: %w", err)
+		}
+		_, err = os.Stdout.Write(out)
+		return err
+	case "env":
+		for _, ev := range vars {
+			fmt.Printf("%s=%s\n", ev.Name, shellQuote(ev.Value))
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q (expected text, json, yaml or env)", outputFormat)
+	}
+}
+
+// shellQuote quotes a value so it can be sourced by a POSIX shell
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'"'"'`) + "'"
+}
@@ -5,21 +5,23 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
+	"github.com/frkr-io/frkr-operator/internal/naming"
 )
 
 var userCmd = &cobra.Command{
 	Use:   "user",
 	Short: "Manage users",
-	Long:  `Create, list, reset passwords, and delete users via the operator.`,
+	Long:  `Create, import, list, reset passwords, disable, and delete users via the operator.`,
 }
 
 var userCreateCmd = &cobra.Command{
@@ -30,6 +32,15 @@ var userCreateCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		username := args[0]
 		tenantID, _ := cmd.Flags().GetString("tenant-id")
+		passwordSecretRef, _ := cmd.Flags().GetString("password-secret-ref")
+
+		if tenantID == "" {
+			return fmt.Errorf("--tenant-id is required")
+		}
+		if err := validateExportFlags(cmd); err != nil {
+			return err
+		}
+		exporting := exportRequested(cmd)
 
 		// Get k8s client
 		k8sClient, err := getK8sClient()
@@ -43,10 +54,10 @@ var userCreateCmd = &cobra.Command{
 			return err
 		}
 
-		// Create FrkrUser CRD
+		// Create FrkrUser CRD, named after the tenant so same-named users of different tenants can coexist
 		user := &frkrv1.FrkrUser{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      username,
+				Name:      naming.UserResourceName(tenantID, username),
 				Namespace: ns,
 			},
 			Spec: frkrv1.FrkrUserSpec{
@@ -54,12 +65,16 @@ var userCreateCmd = &cobra.Command{
 				TenantID: tenantID,
 			},
 		}
+		if passwordSecretRef != "" {
+			name, key, _ := strings.Cut(passwordSecretRef, "/")
+			user.Spec.PasswordSecretRef = &frkrv1.SecretKeyReference{Name: name, Key: key}
+		}
 
 		if err := k8sClient.Create(context.Background(), user); err != nil {
 			return fmt.Errorf("failed to create user: %w", err)
 		}
 
-		if outputFormat != "json" {
+		if outputFormat != "json" && !exporting {
 			fmt.Printf("✅ User %s created successfully\n", username)
 			fmt.Println("Waiting for password generation...")
 		}
@@ -73,18 +88,34 @@ var userCreateCmd = &cobra.Command{
 		for {
 			select {
 			case <-timeout:
-				if outputFormat == "json" {
+				if outputFormat == "json" || exporting {
 					return fmt.Errorf("timed out waiting for password (%ds)", timeoutSeconds)
 				}
-				fmt.Printf("⚠️  Timed out waiting for password (%ds). Check status with: kubectl get secret frkr-user-%s -o yaml\n", timeoutSeconds, username)
+				fmt.Printf("⚠️  Timed out waiting for password (%ds). Check status with: kubectl get frkruser %s -o yaml\n", timeoutSeconds, user.Name)
 				return nil
 			case <-ticker.C:
+				var current frkrv1.FrkrUser
+				if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(user), &current); err != nil {
+					continue
+				}
+				for _, conflict := range []string{"UsernameConflict", "SecretConflict"} {
+					if cond := meta.FindStatusCondition(current.Status.Conditions, conflict); cond != nil && cond.Status == metav1.ConditionTrue {
+						return fmt.Errorf("user was not created: %s", cond.Message)
+					}
+				}
+				if current.Status.CredentialsSecret == "" {
+					continue
+				}
+
 				var secret corev1.Secret
 				if err := k8sClient.Get(context.Background(), client.ObjectKey{
-					Name:      fmt.Sprintf("frkr-user-%s", username),
+					Name:      current.Status.CredentialsSecret,
 					Namespace: ns,
 				}, &secret); err == nil {
 					if pass, ok := secret.Data["password"]; ok {
+						if exporting {
+							return writeExport(context.Background(), cmd, k8sClient, userExport(&current, &secret))
+						}
 						if outputFormat == "json" {
 							// JSON Output
 							out := map[string]string{
@@ -150,7 +181,7 @@ var userListCmd = &cobra.Command{
 var userResetPasswordCmd = &cobra.Command{
 	Use:   "reset-password [username]",
 	Short: "Reset a user's password",
-	Long:  `Reset a user's password (updates FrkrUser CRD to trigger reset).`,
+	Long:  `Reset a user's password (bumps the FrkrUser reset generation and waits for the new password).`,
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		username := args[0]
@@ -165,31 +196,98 @@ var userResetPasswordCmd = &cobra.Command{
 			return err
 		}
 
-		var user frkrv1.FrkrUser
-		if err := k8sClient.Get(context.Background(), client.ObjectKey{
-			Name:      username,
-			Namespace: ns,
-		}, &user); err != nil {
-			return fmt.Errorf("failed to get user: %w", err)
+		tenantID, _ := cmd.Flags().GetString("tenant-id")
+		user, err := findUser(context.Background(), k8sClient, ns, username, tenantID)
+		if err != nil {
+			return err
+		}
+		key := client.ObjectKeyFromObject(user)
+		if err := validateExportFlags(cmd); err != nil {
+			return err
+		}
+		exporting := exportRequested(cmd)
+
+		// A referenced password is managed in its Secret; the operator rejects the reset
+		if ref := user.Spec.PasswordSecretRef; ref != nil {
+			return fmt.Errorf("user %s reads its password from Secret %s; change the password there", username, ref.Name)
 		}
 
-		// Clear password to trigger regeneration
+		// Clear any inline password and request a new generation to trigger regeneration
 		user.Spec.Password = ""
-		if err := k8sClient.Update(context.Background(), &user); err != nil {
+		user.Spec.PasswordResetGeneration++
+		generation := user.Spec.PasswordResetGeneration
+		if err := k8sClient.Update(context.Background(), user); err != nil {
 			return fmt.Errorf("failed to reset password: %w", err)
 		}
 
-		fmt.Printf("✅ Password reset for user %s\n", username)
-		fmt.Printf("Check new password with: kubectl get frkruser %s -o jsonpath='{.status.password}'\n", username)
-		return nil
+		if outputFormat != "json" && !exporting {
+			fmt.Printf("✅ Password reset requested for user %s\n", username)
+			fmt.Println("Waiting for new password...")
+		}
+
+		// Poll until the operator has applied the reset
+		timeoutSeconds, _ := cmd.Flags().GetInt("timeout")
+		timeout := time.After(time.Duration(timeoutSeconds) * time.Second)
+		ticker := time.NewTicker(1 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-timeout:
+				return fmt.Errorf("timed out waiting for password reset (%ds); check status with: kubectl get frkruser %s -o yaml", timeoutSeconds, key.Name)
+			case <-ticker.C:
+				var updated frkrv1.FrkrUser
+				if err := k8sClient.Get(context.Background(), key, &updated); err != nil {
+					continue
+				}
+				if updated.Status.ObservedPasswordResetGeneration < generation {
+					continue
+				}
+
+				if updated.Status.CredentialsSecret == "" {
+					continue
+				}
+				var secret corev1.Secret
+				if err := k8sClient.Get(context.Background(), client.ObjectKey{
+					Name:      updated.Status.CredentialsSecret,
+					Namespace: ns,
+				}, &secret); err != nil {
+					continue
+				}
+				pass := string(secret.Data["password"])
+
+				if exporting {
+					return writeExport(context.Background(), cmd, k8sClient, userExport(&updated, &secret))
+				}
+				if outputFormat == "json" {
+					out := map[string]string{
+						"username":  updated.Spec.Username,
+						"password":  pass,
+						"tenant_id": updated.Spec.TenantID,
+						"status":    "reset",
+					}
+					if updated.Status.LastPasswordReset != nil {
+						out["last_password_reset"] = updated.Status.LastPasswordReset.UTC().Format(time.RFC3339)
+					}
+					return json.NewEncoder(os.Stdout).Encode(out)
+				}
+				fmt.Printf("\n🔑 New password: %s\n\n", pass)
+				fmt.Println("Save this password! It will not be shown again.")
+				return nil
+			}
+		}
 	},
 }
 
 var userDeleteCmd = &cobra.Command{
 	Use:   "delete [username]",
 	Short: "Delete a user",
-	Long:  `Delete a user (deletes FrkrUser CRD).`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Delete a user (deletes FrkrUser CRD).
+
+The operator removes the user from the database before the CRD is released.
+Use --keep-db-record to retain the database record for audit instead; it is
+disabled and can no longer authenticate.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		username := args[0]
 
@@ -203,29 +301,200 @@ var userDeleteCmd = &cobra.Command{
 			return err
 		}
 
-		var user frkrv1.FrkrUser
-		if err := k8sClient.Get(context.Background(), client.ObjectKey{
-			Name:      username,
-			Namespace: ns,
-		}, &user); err != nil {
-			return fmt.Errorf("failed to get user: %w", err)
+		tenantID, _ := cmd.Flags().GetString("tenant-id")
+		user, err := findUser(context.Background(), k8sClient, ns, username, tenantID)
+		if err != nil {
+			return err
 		}
 
-		if err := k8sClient.Delete(context.Background(), &user); err != nil {
+		keepRecord, _ := cmd.Flags().GetBool("keep-db-record")
+		if keepRecord && user.Spec.DeletionPolicy != frkrv1.UserDeletionPolicyRetain {
+			user.Spec.DeletionPolicy = frkrv1.UserDeletionPolicyRetain
+			if err := k8sClient.Update(context.Background(), user); err != nil {
+				return fmt.Errorf("failed to set deletion policy: %w", err)
+			}
+		}
+
+		if err := k8sClient.Delete(context.Background(), user); err != nil {
 			return fmt.Errorf("failed to delete user: %w", err)
 		}
 
-		fmt.Printf("✅ User %s deleted\n", username)
+		if user.Spec.DeletionPolicy == frkrv1.UserDeletionPolicyRetain {
+			fmt.Printf("✅ User %s deleted (database record retained for audit)\n", username)
+		} else {
+			fmt.Printf("✅ User %s deleted\n", username)
+		}
+		return nil
+	},
+}
+
+var userDisableCmd = &cobra.Command{
+	Use:   "disable [username]",
+	Short: "Disable or temporarily lock a user",
+	Long: `Revoke a user's access without deleting it (updates FrkrUser CRD).
+
+Without --for or --until the user stays disabled until 'frkrctl user enable'.
+With --for or --until the user is locked and regains access automatically.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		username := args[0]
+		reason, _ := cmd.Flags().GetString("reason")
+		lockFor, _ := cmd.Flags().GetDuration("for")
+		until, _ := cmd.Flags().GetString("until")
+
+		if lockFor > 0 && until != "" {
+			return fmt.Errorf("--for and --until are mutually exclusive")
+		}
+
+		var lockedUntil *metav1.Time
+		if lockFor > 0 {
+			t := metav1.NewTime(time.Now().Add(lockFor))
+			lockedUntil = &t
+		} else if until != "" {
+			parsed, err := time.Parse(time.RFC3339, until)
+			if err != nil {
+				return fmt.Errorf("invalid --until (expected RFC3339): %w", err)
+			}
+			t := metav1.NewTime(parsed)
+			lockedUntil = &t
+		}
+
+		k8sClient, err := getK8sClient()
+		if err != nil {
+			return err
+		}
+
+		ns, err := getNamespace()
+		if err != nil {
+			return err
+		}
+
+		tenantID, _ := cmd.Flags().GetString("tenant-id")
+		user, err := findUser(context.Background(), k8sClient, ns, username, tenantID)
+		if err != nil {
+			return err
+		}
+
+		user.Spec.Disabled = lockedUntil == nil
+		user.Spec.LockedUntil = lockedUntil
+		user.Spec.LockReason = reason
+		if err := k8sClient.Update(context.Background(), user); err != nil {
+			return fmt.Errorf("failed to disable user: %w", err)
+		}
+
+		if lockedUntil != nil {
+			fmt.Printf("✅ User %s locked until %s\n", username, lockedUntil.UTC().Format(time.RFC3339))
+		} else {
+			fmt.Printf("✅ User %s disabled\n", username)
+		}
 		return nil
 	},
 }
 
+var userEnableCmd = &cobra.Command{
+	Use:   "enable [username]",
+	Short: "Re-enable a disabled or locked user",
+	Long:  `Restore a user's access by clearing any disable or lock (updates FrkrUser CRD).`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		username := args[0]
+
+		k8sClient, err := getK8sClient()
+		if err != nil {
+			return err
+		}
+
+		ns, err := getNamespace()
+		if err != nil {
+			return err
+		}
+
+		tenantID, _ := cmd.Flags().GetString("tenant-id")
+		user, err := findUser(context.Background(), k8sClient, ns, username, tenantID)
+		if err != nil {
+			return err
+		}
+
+		user.Spec.Disabled = false
+		user.Spec.LockedUntil = nil
+		user.Spec.LockReason = ""
+		if err := k8sClient.Update(context.Background(), user); err != nil {
+			return fmt.Errorf("failed to enable user: %w", err)
+		}
+
+		fmt.Printf("✅ User %s enabled\n", username)
+		return nil
+	},
+}
+
+// findUser returns the FrkrUser for a username, optionally restricted to a tenant.
+// Users created before tenant-qualified naming are found as well.
+func findUser(ctx context.Context, k8sClient client.Client, ns, username, tenantID string) (*frkrv1.FrkrUser, error) {
+	var userList frkrv1.FrkrUserList
+	if err := k8sClient.List(ctx, &userList, client.InNamespace(ns)); err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	var matches []*frkrv1.FrkrUser
+	for i := range userList.Items {
+		u := &userList.Items[i]
+		if u.Spec.Username == username && (tenantID == "" || u.Spec.TenantID == tenantID) {
+			matches = append(matches, u)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		if tenantID != "" {
+			return nil, fmt.Errorf("user %s not found in tenant %s", username, tenantID)
+		}
+		return nil, fmt.Errorf("user %s not found", username)
+	case 1:
+		return matches[0], nil
+	default:
+		var tenants []string
+		for _, u := range matches {
+			tenants = append(tenants, u.Spec.TenantID)
+		}
+		return nil, fmt.Errorf("user %s exists in several tenants (%s); use --tenant-id", username, strings.Join(tenants, ", "))
+	}
+}
+
+// userExport describes the credentials in a user's Secret for --format
+func userExport(user *frkrv1.FrkrUser, secret *corev1.Secret) credentialExport {
+	password := string(secret.Data["password"])
+	return credentialExport{
+		Name:      secret.Name,
+		Namespace: secret.Namespace,
+		Fields: []exportField{
+			{Key: "username", EnvVar: "FRKR_USERNAME", SecretKey: "username", Value: user.Spec.Username},
+			{Key: "tenant_id", EnvVar: "FRKR_TENANT_ID", SecretKey: "tenantId", Value: user.Spec.TenantID},
+			{Key: "password", EnvVar: "FRKR_PASSWORD", SecretKey: "password", Value: password},
+		},
+		Login:    user.Spec.Username,
+		Password: password,
+	}
+}
+
 func init() {
 	userCreateCmd.Flags().String("tenant-id", "", "Tenant ID (required)")
 	userCreateCmd.Flags().Int("timeout", 90, "Timeout in seconds to wait for password generation")
+	userCreateCmd.Flags().String("password-secret-ref", "", "Read the password from a Kubernetes Secret (name[/key], key defaults to password)")
+	userResetPasswordCmd.Flags().Int("timeout", 90, "Timeout in seconds to wait for the new password")
+	addExportFlags(userCreateCmd)
+	addExportFlags(userResetPasswordCmd)
+	userDeleteCmd.Flags().Bool("keep-db-record", false, "Retain the disabled database record for audit")
+	for _, cmd := range []*cobra.Command{userResetPasswordCmd, userDeleteCmd, userDisableCmd, userEnableCmd} {
+		cmd.Flags().String("tenant-id", "", "Tenant ID (required if the username exists in several tenants)")
+	}
 	userCmd.AddCommand(userCreateCmd)
 	userCmd.AddCommand(userListCmd)
 	userCmd.AddCommand(userResetPasswordCmd)
 	userCmd.AddCommand(userDeleteCmd)
+	userDisableCmd.Flags().String("reason", "", "Reason shown in status and events")
+	userDisableCmd.Flags().Duration("for", 0, "Lock the user for a duration (e.g. 72h) instead of disabling it")
+	userDisableCmd.Flags().String("until", "", "Lock the user until an RFC3339 timestamp instead of disabling it")
+	userCmd.AddCommand(userDisableCmd)
+	userCmd.AddCommand(userEnableCmd)
 	rootCmd.AddCommand(userCmd)
 }
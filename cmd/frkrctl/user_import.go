@@ -0,0 +1,592 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/scrypt"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
+	"github.com/frkr-io/frkr-operator/internal/naming"
+)
+
+// reportPassphraseEnv holds the passphrase used to encrypt and decrypt credential reports
+const reportPassphraseEnv = "FRKR_REPORT_PASSPHRASE"
+
+// encryptedReportMagic prefixes encrypted credential reports
+var encryptedReportMagic = []byte("FRKRENC1")
+
+// importUser is one user entry of an import file
+type importUser struct {
+	Username string   `json:"username"`
+	TenantID string   `json:"tenantId"`
+	Roles    []string `json:"roles,omitempty"`
+
+	// line is the CSV line or YAML list index the entry came from
+	line int
+}
+
+// importResult is the outcome of importing a single user
+type importResult struct {
+	Username string
+	TenantID string
+	Status   string
+	Password string
+	// CredentialsSecret is the Secret holding the user's credentials, also for skipped users
+	CredentialsSecret string
+	Error             string
+}
+
+var userImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Create users in bulk from a CSV or YAML file",
+	Long: `Create users in bulk from a CSV or YAML file.
+
+CSV files need a header row with the columns username and tenant_id, plus an
+optional roles column whose role names are separated by ';'. YAML files contain
+a list of entries with username, tenantId and roles.
+
+All entries are validated before anything is created. Users that already exist
+are skipped, so an interrupted import can be re-run; the report names the
+credentials Secret of every user, so passwords of users created by an
+interrupted run can be read from there. Generated passwords are added to the
+--report file (mode 0600) as each user becomes Active. The report must not
+exist yet so a re-run cannot overwrite the passwords of an earlier one; with
+--encrypt the report is encrypted using the passphrase in
+$FRKR_REPORT_PASSPHRASE and can be read back with 'frkrctl user decrypt-report'.
+With -o json the report holds one JSON object per line.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+		reportPath, _ := cmd.Flags().GetString("report")
+		encrypt, _ := cmd.Flags().GetBool("encrypt")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		timeoutSeconds, _ := cmd.Flags().GetInt("timeout")
+
+		if file == "" {
+			return fmt.Errorf("--file is required")
+		}
+		if concurrency < 1 {
+			return fmt.Errorf("--concurrency must be at least 1")
+		}
+		if !dryRun && reportPath == "" {
+			return fmt.Errorf("--report is required to save the generated passwords")
+		}
+		passphrase := os.Getenv(reportPassphraseEnv)
+		if encrypt && passphrase == "" {
+			return fmt.Errorf("--encrypt requires the passphrase in $%s", reportPassphraseEnv)
+		}
+		users, err := readImportFile(file)
+		if err != nil {
+			return err
+		}
+		if problems := validateImportUsers(users); len(problems) > 0 {
+			for _, p := range problems {
+				fmt.Fprintf(os.Stderr, "❌ %s\n", p)
+			}
+			return fmt.Errorf("%d invalid entries in %s; nothing was imported", len(problems), file)
+		}
+
+		k8sClient, err := getK8sClient()
+		if err != nil {
+			return err
+		}
+
+		ns, err := getNamespace()
+		if err != nil {
+			return err
+		}
+
+		// Skip users that already exist so re-runs are idempotent
+		ctx := context.Background()
+		var existing frkrv1.FrkrUserList
+		if err := k8sClient.List(ctx, &existing, client.InNamespace(ns)); err != nil {
+			return fmt.Errorf("failed to list users: %w", err)
+		}
+		exists := make(map[string]*frkrv1.FrkrUser)
+		for i := range existing.Items {
+			u := &existing.Items[i]
+			exists[u.Spec.TenantID+"/"+u.Spec.Username] = u
+		}
+
+		var toCreate []importUser
+		var results []importResult
+		for _, u := range users {
+			if found := exists[u.TenantID+"/"+u.Username]; found != nil {
+				results = append(results, importResult{
+					Username:          u.Username,
+					TenantID:          u.TenantID,
+					Status:            "skipped",
+					CredentialsSecret: found.Status.CredentialsSecret,
+				})
+				continue
+			}
+			toCreate = append(toCreate, u)
+		}
+
+		if dryRun {
+			fmt.Printf("Dry run: %d user(s) would be created, %d skipped (already exist)\n", len(toCreate), len(results))
+			for _, u := range toCreate {
+				fmt.Printf("  + %s (tenant %s)\n", u.Username, u.TenantID)
+			}
+			for _, r := range results {
+				fmt.Printf("  = %s (exists)\n", r.Username)
+			}
+			return nil
+		}
+
+		// Reserve the report before creating users: their passwords only end up in the report,
+		// which receives every result as it completes
+		report, err := createImportReport(reportPath, encrypt, passphrase)
+		if err != nil {
+			return err
+		}
+		for _, r := range results {
+			_ = report.Add(r)
+		}
+
+		fmt.Printf("Importing %d user(s) (%d already exist)...\n", len(toCreate), len(results))
+		timeout := time.Duration(timeoutSeconds) * time.Second
+		results = append(results, createImportUsers(ctx, k8sClient, ns, toCreate, concurrency, timeout, func(r importResult) {
+			_ = report.Add(r)
+		})...)
+		if err := report.Close(); err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, r := range results {
+			switch r.Status {
+			case "created":
+				fmt.Printf("✅ %s\n", r.Username)
+			case "skipped":
+				fmt.Printf("⏭️  %s (already exists; credentials in Secret %s)\n", r.Username, r.CredentialsSecret)
+			default:
+				failed++
+				fmt.Printf("❌ %s: %s\n", r.Username, r.Error)
+			}
+		}
+		fmt.Printf("\nCredential report written to %s\n", reportPath)
+		if failed > 0 {
+			return fmt.Errorf("%d user(s) failed to import; re-run to retry", failed)
+		}
+		return nil
+	},
+}
+
+var userDecryptReportCmd = &cobra.Command{
+	Use:   "decrypt-report [file]",
+	Short: "Print an encrypted credential report",
+	Long:  `Decrypt a credential report written by 'frkrctl user import --encrypt' using the passphrase in $FRKR_REPORT_PASSPHRASE.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		passphrase := os.Getenv(reportPassphraseEnv)
+		if passphrase == "" {
+			return fmt.Errorf("the passphrase must be set in $%s", reportPassphraseEnv)
+		}
+
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to read report: %w", err)
+		}
+		plaintext, err := decryptReport(data, passphrase)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(plaintext)
+		return err
+	},
+}
+
+// readImportFile parses users from a CSV or YAML file, chosen by extension
+func readImportFile(path string) ([]importUser, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return parseImportCSV(data)
+	case ".yaml", ".yml":
+		var users []importUser
+		if err := yaml.Unmarshal(data, &users); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		for i := range users {
+			users[i].line = i + 1
+		}
+		return users, nil
+	default:
+		return nil, fmt.Errorf("unsupported file type %q (use .csv, .yaml or .yml)", filepath.Ext(path))
+	}
+}
+
+// parseImportCSV parses a CSV import file with a header row
+func parseImportCSV(data []byte) ([]importUser, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.TrimLeadingSpace = true
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["username"]; !ok {
+		return nil, fmt.Errorf("CSV header is missing the username column")
+	}
+	if _, ok := columns["tenant_id"]; !ok {
+		return nil, fmt.Errorf("CSV header is missing the tenant_id column")
+	}
+
+	field := func(record []string, column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var users []importUser
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV line %d: %w", line, err)
+		}
+
+		u := importUser{
+			Username: field(record, "username"),
+			TenantID: field(record, "tenant_id"),
+			line:     line,
+		}
+		if roles := field(record, "roles"); roles != "" {
+			for _, role := range strings.Split(roles, ";") {
+				u.Roles = append(u.Roles, strings.TrimSpace(role))
+			}
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+// validateImportUsers returns every problem found in the entries
+func validateImportUsers(users []importUser) []string {
+	var problems []string
+	if len(users) == 0 {
+		return []string{"no users found"}
+	}
+
+	seen := make(map[string]int)
+	for _, u := range users {
+		key := u.TenantID + "/" + u.Username
+		if u.Username == "" {
+			problems = append(problems, fmt.Sprintf("line %d: username is required", u.line))
+		} else if errs := validation.IsDNS1123Subdomain(u.Username); len(errs) > 0 {
+			problems = append(problems, fmt.Sprintf("line %d: invalid username %q: %s", u.line, u.Username, strings.Join(errs, "; ")))
+		}
+		if u.TenantID == "" {
+			problems = append(problems, fmt.Sprintf("line %d: tenant_id is required", u.line))
+		}
+		for _, role := range u.Roles {
+			if role == "" {
+				problems = append(problems, fmt.Sprintf("line %d: empty role name", u.line))
+			}
+		}
+		if first, ok := seen[key]; ok && u.Username != "" {
+			problems = append(problems, fmt.Sprintf("line %d: duplicate username %q in tenant %q (first on line %d)", u.line, u.Username, u.TenantID, first))
+		} else {
+			seen[key] = u.line
+		}
+	}
+	return problems
+}
+
+// createImportUsers creates the users with at most concurrency requests in flight and passes
+// every result to done as soon as it is known
+func createImportUsers(ctx context.Context, k8sClient client.Client, ns string, users []importUser, concurrency int, timeout time.Duration, done func(importResult)) []importResult {
+	results := make([]importResult, len(users))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, u := range users {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = createImportUser(ctx, k8sClient, ns, u, timeout)
+			done(results[i])
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// createImportUser creates a single user and waits for it to become Active
+func createImportUser(ctx context.Context, k8sClient client.Client, ns string, u importUser, timeout time.Duration) importResult {
+	result := importResult{Username: u.Username, TenantID: u.TenantID}
+
+	user := &frkrv1.FrkrUser{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      naming.UserResourceName(u.TenantID, u.Username),
+			Namespace: ns,
+		},
+		Spec: frkrv1.FrkrUserSpec{
+			Username: u.Username,
+			TenantID: u.TenantID,
+			Roles:    u.Roles,
+		},
+	}
+	if err := k8sClient.Create(ctx, user); err != nil {
+		result.Status = "failed"
+		result.Error = fmt.Sprintf("failed to create user: %v", err)
+		return result
+	}
+
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-deadline:
+			result.Status = "failed"
+			result.Error = fmt.Sprintf("timed out waiting for the user to become Active (%s)", timeout)
+			return result
+		case <-ticker.C:
+			var current frkrv1.FrkrUser
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(user), &current); err != nil {
+				continue
+			}
+			if current.Status.Phase == "Rejected" || current.Status.Phase == "Conflict" {
+				result.Status = "failed"
+				result.Error = fmt.Sprintf("user was not created (phase %s)", current.Status.Phase)
+				return result
+			}
+			if current.Status.Phase != "Active" || current.Status.CredentialsSecret == "" {
+				continue
+			}
+
+			var secret corev1.Secret
+			if err := k8sClient.Get(ctx, client.ObjectKey{
+				Name:      current.Status.CredentialsSecret,
+				Namespace: ns,
+			}, &secret); err != nil {
+				continue
+			}
+			result.Status = "created"
+			result.Password = string(secret.Data["password"])
+			result.CredentialsSecret = secret.Name
+			return result
+		}
+	}
+}
+
+// importReport is the credential report of an import, written as CSV (or JSON lines with
+// -o json) and optionally encrypted. Results are added as they complete, so an interrupted
+// import keeps the passwords of the users it created.
+type importReport struct {
+	mu   sync.Mutex
+	f    *os.File
+	json bool
+	aead cipher.AEAD
+	err  error
+}
+
+// createImportReport creates the report file, readable only by the current user. An
+// existing file is never replaced: it may hold the only copy of earlier passwords.
+func createImportReport(path string, encrypt bool, passphrase string) (*importReport, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("report %s already exists; choose a new --report file", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open report: %w", err)
+	}
+	report := &importReport{f: f, json: outputFormat == "json"}
+
+	if encrypt {
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			f.Close()
+			return nil, err
+		}
+		if report.aead, err = reportCipher(passphrase, salt); err != nil {
+			f.Close()
+			return nil, err
+		}
+		if _, err := f.Write(append(append([]byte{}, encryptedReportMagic...), salt...)); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+	if !report.json {
+		if err := report.writeCSV([]string{"username", "tenant_id", "status", "password", "credentials_secret", "error"}); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// Add appends a result to the report and flushes it to disk
+func (r *importReport) Add(result importResult) error {
+	if !r.json {
+		return r.writeCSV([]string{result.Username, result.TenantID, result.Status, result.Password, result.CredentialsSecret, result.Error})
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(map[string]string{
+		"username":           result.Username,
+		"tenant_id":          result.TenantID,
+		"status":             result.Status,
+		"password":           result.Password,
+		"credentials_secret": result.CredentialsSecret,
+		"error":              result.Error,
+	}); err != nil {
+		return err
+	}
+	return r.write(buf.Bytes())
+}
+
+// Close closes the report and returns the first error writing it
+func (r *importReport) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.f.Close(); err != nil && r.err == nil {
+		r.err = fmt.Errorf("failed to write report: %w", err)
+	}
+	return r.err
+}
+
+func (r *importReport) writeCSV(record []string) error {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(record)
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return r.write(buf.Bytes())
+}
+
+// write appends data, sealed as a record of its own when the report is encrypted
+func (r *importReport) write(data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	if r.aead != nil {
+		var err error
+		if data, err = sealReportRecord(r.aead, data); err != nil {
+			r.err = err
+			return err
+		}
+	}
+	if _, err := r.f.Write(data); err != nil {
+		r.err = fmt.Errorf("failed to write report: %w", err)
+		return r.err
+	}
+	if err := r.f.Sync(); err != nil {
+		r.err = fmt.Errorf("failed to write report: %w", err)
+	}
+	return r.err
+}
+
+// reportCipher derives the AES-256-GCM cipher for a credential report from the passphrase
+func reportCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive report key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealReportRecord encrypts one record of a report as length | nonce | AES-GCM ciphertext;
+// the length covers nonce and ciphertext. An encrypted report is magic | salt | records.
+func sealReportRecord(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, encryptedReportMagic)
+	return append(binary.BigEndian.AppendUint32(nil, uint32(len(sealed))), sealed...), nil
+}
+
+// decryptReport decrypts every record of an encrypted report
+func decryptReport(data []byte, passphrase string) ([]byte, error) {
+	if !bytes.HasPrefix(data, encryptedReportMagic) {
+		return nil, fmt.Errorf("not an encrypted credential report")
+	}
+	data = data[len(encryptedReportMagic):]
+	if len(data) < 16 {
+		return nil, fmt.Errorf("encrypted report is truncated")
+	}
+	aead, err := reportCipher(passphrase, data[:16])
+	if err != nil {
+		return nil, err
+	}
+	data = data[16:]
+
+	var plaintext []byte
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, fmt.Errorf("encrypted report is truncated")
+		}
+		n := binary.BigEndian.Uint32(data)
+		data = data[4:]
+		if uint32(len(data)) < n || int(n) < aead.NonceSize() {
+			return nil, fmt.Errorf("encrypted report is truncated")
+		}
+		record := data[:n]
+		data = data[n:]
+		plaintext, err = aead.Open(plaintext, record[:aead.NonceSize()], record[aead.NonceSize():], encryptedReportMagic)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt report (wrong passphrase?): %w", err)
+		}
+	}
+	return plaintext, nil
+}
+
+func init() {
+	userImportCmd.Flags().StringP("file", "f", "", "CSV or YAML file with the users to import (required)")
+	userImportCmd.Flags().String("report", "", "File to write the credential report to (required unless --dry-run)")
+	userImportCmd.Flags().Bool("encrypt", false, "Encrypt the report with the passphrase in $"+reportPassphraseEnv)
+	userImportCmd.Flags().Bool("dry-run", false, "Validate the file and show which users would be created")
+	userImportCmd.Flags().Int("concurrency", 5, "Maximum number of users created in parallel")
+	userImportCmd.Flags().Int("timeout", 90, "Timeout in seconds to wait for each user to become Active")
+	userCmd.AddCommand(userImportCmd)
+	userCmd.AddCommand(userDecryptReportCmd)
+}
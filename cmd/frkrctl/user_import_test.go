@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseImportCSV(t *testing.T) {
+	data := []byte("Username, tenant_id, roles\nalice, tenant-1, reader; writer\nbob,tenant-2\n")
+
+	users, err := parseImportCSV(data)
+	if err != nil {
+		t.Fatalf("parseImportCSV() error = %v", err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("parseImportCSV() returned %d users, want 2", len(users))
+	}
+	if u := users[0]; u.Username != "alice" || u.TenantID != "tenant-1" || !slices.Equal(u.Roles, []string{"reader", "writer"}) || u.line != 2 {
+		t.Errorf("first user = %+v", u)
+	}
+	if u := users[1]; u.Username != "bob" || u.TenantID != "tenant-2" || u.Roles != nil || u.line != 3 {
+		t.Errorf("second user = %+v", u)
+	}
+}
+
+func TestParseImportCSV_MissingColumn(t *testing.T) {
+	for _, header := range []string{"username,roles\n", "tenant_id\n", ""} {
+		if _, err := parseImportCSV([]byte(header)); err == nil {
+			t.Errorf("parseImportCSV(%q) succeeded, want an error", header)
+		}
+	}
+}
+
+func TestReadImportFile(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "users.yaml")
+	content := "- username: alice\n  tenantId: tenant-1\n  roles: [reader]\n- username: bob\n  tenantId: tenant-1\n"
+	if err := os.WriteFile(yamlPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	users, err := readImportFile(yamlPath)
+	if err != nil {
+		t.Fatalf("readImportFile() error = %v", err)
+	}
+	if len(users) != 2 || users[0].Username != "alice" || !slices.Equal(users[0].Roles, []string{"reader"}) || users[1].line != 2 {
+		t.Errorf("readImportFile() = %+v", users)
+	}
+
+	txtPath := filepath.Join(dir, "users.txt")
+	if err := os.WriteFile(txtPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readImportFile(txtPath); err == nil {
+		t.Error("readImportFile() accepted an unsupported extension")
+	}
+}
+
+func TestValidateImportUsers(t *testing.T) {
+	tests := []struct {
+		name  string
+		users []importUser
+		want  []string
+	}{
+		{
+			name:  "valid",
+			users: []importUser{{Username: "alice", TenantID: "tenant-1", line: 2}, {Username: "alice", TenantID: "tenant-2", line: 3}},
+		},
+		{
+			name: "empty file",
+			want: []string{"no users found"},
+		},
+		{
+			name:  "missing fields",
+			users: []importUser{{line: 2}},
+			want:  []string{"line 2: username is required", "line 2: tenant_id is required"},
+		},
+		{
+			name:  "invalid username",
+			users: []importUser{{Username: "Alice_1", TenantID: "tenant-1", line: 4}},
+			want:  []string{"line 4: invalid username"},
+		},
+		{
+			name:  "empty role",
+			users: []importUser{{Username: "alice", TenantID: "tenant-1", Roles: []string{"reader", ""}, line: 2}},
+			want:  []string{"line 2: empty role name"},
+		},
+		{
+			name:  "duplicate in tenant",
+			users: []importUser{{Username: "alice", TenantID: "tenant-1", line: 2}, {Username: "alice", TenantID: "tenant-1", line: 5}},
+			want:  []string{"line 5: duplicate username \"alice\" in tenant \"tenant-1\" (first on line 2)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := validateImportUsers(tt.users)
+			if len(problems) != len(tt.want) {
+				t.Fatalf("validateImportUsers() = %q, want %d problem(s)", problems, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.HasPrefix(problems[i], want) {
+					t.Errorf("problem %d = %q, want prefix %q", i, problems[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestImportReportEncryptionRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.enc")
+	report, err := createImportReport(path, true, "correct horse")
+	if err != nil {
+		t.Fatalf("createImportReport() error = %v", err)
+	}
+	if err := report.Add(importResult{Username: "alice", TenantID: "tenant-1", Status: "created", Password: "s3cr3t"}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := report.Add(importResult{Username: "bob", TenantID: "tenant-1", Status: "skipped", CredentialsSecret: "frkr-user-bob"}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := report.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	encrypted, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(encrypted, encryptedReportMagic) || bytes.Contains(encrypted, []byte("s3cr3t")) {
+		t.Fatal("encrypted report is not in the expected format")
+	}
+
+	decrypted, err := decryptReport(encrypted, "correct horse")
+	if err != nil {
+		t.Fatalf("decryptReport() error = %v", err)
+	}
+	want := "username,tenant_id,status,password,credentials_secret,error\n" +
+		"alice,tenant-1,created,s3cr3t,,\n" +
+		"bob,tenant-1,skipped,,frkr-user-bob,\n"
+	if string(decrypted) != want {
+		t.Errorf("decryptReport() = %q, want %q", decrypted, want)
+	}
+
+	if _, err := decryptReport(encrypted, "wrong passphrase"); err == nil {
+		t.Error("decryptReport() accepted a wrong passphrase")
+	}
+	if _, err := decryptReport(encrypted[:len(encrypted)-3], "correct horse"); err == nil {
+		t.Error("decryptReport() accepted a truncated report")
+	}
+	if _, err := decryptReport([]byte(want), "correct horse"); err == nil {
+		t.Error("decryptReport() accepted an unencrypted report")
+	}
+}
+
+func TestImportReport_WritesResultsAsTheyComplete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.csv")
+	report, err := createImportReport(path, false, "")
+	if err != nil {
+		t.Fatalf("createImportReport() error = %v", err)
+	}
+	defer report.Close()
+
+	if err := report.Add(importResult{Username: "alice", TenantID: "tenant-1", Status: "created", Password: "first"}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	// The password is on disk before the import finishes
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte("alice,tenant-1,created,first")) {
+		t.Errorf("report = %q, want alice's password", data)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("report mode = %o, want 600", perm)
+	}
+}
+
+func TestCreateImportReport_RefusesExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.csv")
+	if err := os.WriteFile(path, []byte("first"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := createImportReport(path, false, ""); err == nil {
+		t.Fatal("createImportReport() accepted an existing report")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "first" {
+		t.Errorf("report was modified: %q", data)
+	}
+}
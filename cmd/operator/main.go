@@ -10,6 +10,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
 	"github.com/frkr-io/frkr-operator/internal/controller"
@@ -32,11 +33,15 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var hashClientSecrets bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&hashClientSecrets, "hash-plaintext-client-secrets", false,
+		"Store client secrets hashed with CLIENT_SECRET_HASH_ALGORITHM and hash those stored in plaintext. "+
+			"Only enable once every gateway verifies hashed secrets; frkr-common v0.3.3 and earlier compare plaintext.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -47,6 +52,7 @@ func main() {
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
+		Metrics:                metricsserver.Options{BindAddress: metricsAddr},
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "frkr-operator.frkr.io",
@@ -74,6 +80,23 @@ func main() {
 		setupLog.Error(err, "unable to connect to database")
 		os.Exit(1)
 	}
+	// Client secrets are only hashed once opted in; gateways on frkr-common v0.3.3 and
+	// earlier compare them as plaintext
+	if hashClientSecrets {
+		db.SecretHash = infraConfig.ClientSecretHash
+	}
+
+	// Hash client secrets stored in plaintext by earlier operator versions when opted in;
+	// progress is served on the metrics server's /status endpoint
+	secretHashUpgrade := &controller.ClientSecretHashUpgrade{DB: db, Enabled: hashClientSecrets}
+	if err := mgr.Add(secretHashUpgrade); err != nil {
+		setupLog.Error(err, "unable to set up client secret hash upgrade")
+		os.Exit(1)
+	}
+	if err := mgr.AddMetricsServerExtraHandler("/status", secretHashUpgrade); err != nil {
+		setupLog.Error(err, "unable to set up status endpoint")
+		os.Exit(1)
+	}
 
 	// Register specific reconcilers that need DB access
 	if err = (&controller.TenantReconciler{
@@ -85,10 +108,18 @@ func main() {
 		os.Exit(1)
 	}
 
+	clientCASecret, err := controller.ClientCASecretFromEnv()
+	if err != nil {
+		setupLog.Error(err, "unable to determine client CA secret")
+		os.Exit(1)
+	}
 	if err = (&controller.ClientReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		DB:     db,
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		DB:         db,
+		KafkaAdmin: infra.NewKafkaAdmin(infraConfig.BrokerURL),
+		Recorder:   mgr.GetEventRecorderFor("frkrclient-controller"),
+		CASecret:   clientCASecret,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "FrkrClient")
 		os.Exit(1)
@@ -0,0 +1,295 @@
+// Package claims evaluates the OIDC claim mappings of a FrkrAuthConfig: the rules that
+// assign a tenant and roles to a user from the claims of their ID token.
+package claims
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
+)
+
+// DefaultGroupsClaim is the claim listing a user's groups unless groupsClaim is set
+const DefaultGroupsClaim = "groups"
+
+// Mapper is a compiled set of claim mappings
+type Mapper struct {
+	groupsClaim string
+	tenant      []tenantRule
+	roles       []roleRule
+}
+
+type tenantRule struct {
+	claim    string
+	match    *regexp.Regexp
+	tenantID string
+}
+
+type roleRule struct {
+	group    *regexp.Regexp
+	role     string
+	tenantID string
+}
+
+// Result is the outcome of evaluating the mappings against a token
+type Result struct {
+	// TenantID is the tenant assigned to the user; empty if no tenant rule matched
+	TenantID string `json:"tenantId,omitempty"`
+	// TenantRule is the index of the tenant rule that matched, or -1
+	TenantRule int `json:"tenantRule"`
+	// Groups are the groups read from the groups claim
+	Groups []string `json:"groups,omitempty"`
+	// Roles are the roles granted, in rule order; none without a tenant
+	Roles []RoleGrant `json:"roles,omitempty"`
+	// Skipped lists rule results that were dropped, with the reason
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// RoleGrant is a role granted by a role rule
+type RoleGrant struct {
+	Role  string `json:"role"`
+	Group string `json:"group"`
+	Rule  int    `json:"rule"`
+}
+
+// Compile checks the mappings and compiles their expressions. All problems are returned,
+// each prefixed with the field of the rule it belongs to.
+func Compile(mappings *frkrv1.ClaimMappings) (*Mapper, error) {
+	m := &Mapper{groupsClaim: DefaultGroupsClaim}
+	if mappings == nil {
+		return m, nil
+	}
+	if mappings.GroupsClaim != "" {
+		m.groupsClaim = mappings.GroupsClaim
+	}
+
+	var errs []error
+	for i, rule := range mappings.Tenant {
+		field := fmt.Sprintf("tenant[%d]", i)
+		if rule.Claim == "" {
+			errs = append(errs, fmt.Errorf("%s.claim: must be set", field))
+		}
+		expr := rule.Match
+		if expr == "" {
+			expr = ".+"
+		}
+		re, err := compileExpression(expr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s.match: %w", field, err))
+			continue
+		}
+		tenantID := rule.TenantID
+		if tenantID == "" {
+			tenantID = "$0"
+		}
+		if err := checkTemplate(re, tenantID); err != nil {
+			errs = append(errs, fmt.Errorf("%s.tenantId: %w", field, err))
+			continue
+		}
+		m.tenant = append(m.tenant, tenantRule{claim: rule.Claim, match: re, tenantID: tenantID})
+	}
+
+	for i, rule := range mappings.Roles {
+		field := fmt.Sprintf("roles[%d]", i)
+		re, err := compileExpression(rule.Group)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s.group: %w", field, err))
+			continue
+		}
+		if rule.Role == "" {
+			errs = append(errs, fmt.Errorf("%s.role: must be set", field))
+			continue
+		}
+		if err := checkTemplate(re, rule.Role); err != nil {
+			errs = append(errs, fmt.Errorf("%s.role: %w", field, err))
+			continue
+		}
+		m.roles = append(m.roles, roleRule{group: re, role: rule.Role, tenantID: rule.TenantID})
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Evaluate applies the mappings to the claims of an ID token
+func (m *Mapper) Evaluate(claims map[string]any) Result {
+	result := Result{TenantRule: -1}
+
+	for i, rule := range m.tenant {
+		value, ok := lookup(claims, rule.claim)
+		if !ok {
+			continue
+		}
+		for _, s := range stringValues(value) {
+			match := rule.match.FindStringSubmatchIndex(s)
+			if match == nil {
+				continue
+			}
+			tenantID := string(rule.match.ExpandString(nil, rule.tenantID, s, match))
+			if tenantID == "" {
+				result.Skipped = append(result.Skipped, fmt.Sprintf("tenant[%d]: produced an empty tenant for %q", i, s))
+				continue
+			}
+			result.TenantID, result.TenantRule = tenantID, i
+			break
+		}
+		if result.TenantRule >= 0 {
+			break
+		}
+	}
+
+	if value, ok := lookup(claims, m.groupsClaim); ok {
+		result.Groups = stringValues(value)
+	}
+	// Users without a tenant are rejected at login, so they get no roles
+	if result.TenantID == "" {
+		return result
+	}
+	granted := map[string]bool{}
+	for i, rule := range m.roles {
+		if rule.tenantID != "" && rule.tenantID != result.TenantID {
+			continue
+		}
+		for _, group := range result.Groups {
+			match := rule.group.FindStringSubmatchIndex(group)
+			if match == nil {
+				continue
+			}
+			role := string(rule.group.ExpandString(nil, rule.role, group, match))
+			if problems := validation.IsDNS1123Subdomain(role); len(problems) > 0 {
+				result.Skipped = append(result.Skipped, fmt.Sprintf("roles[%d]: %q from group %q is not a valid role name", i, role, group))
+				continue
+			}
+			if !granted[role] {
+				granted[role] = true
+				result.Roles = append(result.Roles, RoleGrant{Role: role, Group: group, Rule: i})
+			}
+		}
+	}
+	return result
+}
+
+// RoleNames returns the names of the granted roles
+func (r Result) RoleNames() []string {
+	names := make([]string, len(r.Roles))
+	for i, g := range r.Roles {
+		names[i] = g.Role
+	}
+	return names
+}
+
+// compileExpression compiles an expression that has to match the whole value
+func compileExpression(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, errors.New("must be set")
+	}
+	// Check the expression on its own so errors do not show the anchors added here
+	if _, err := regexp.Compile(expr); err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", expr, err)
+	}
+	return regexp.Compile("^(?:" + expr + ")$")
+}
+
+// checkTemplate checks that the capture group references of a template exist in the
+// expression, using the reference syntax of regexp.Expand
+func checkTemplate(re *regexp.Regexp, template string) error {
+	for template != "" {
+		i := strings.IndexByte(template, '$')
+		if i < 0 {
+			break
+		}
+		template = template[i+1:]
+		if strings.HasPrefix(template, "$") {
+			template = template[1:]
+			continue
+		}
+
+		var name string
+		if strings.HasPrefix(template, "{") {
+			end := strings.IndexByte(template, '}')
+			if end < 0 {
+				return errors.New("unterminated ${ reference")
+			}
+			name, template = template[1:end], template[end+1:]
+		} else {
+			end := 0
+			for end < len(template) && isNameByte(template[end]) {
+				end++
+			}
+			name, template = template[:end], template[end:]
+		}
+		if name == "" {
+			return errors.New("$ must be followed by a capture group number or name (use $$ for a literal $)")
+		}
+		if n, err := strconv.Atoi(name); err == nil {
+			if n > re.NumSubexp() {
+				return fmt.Errorf("references capture group $%d, the expression has %d", n, re.NumSubexp())
+			}
+		} else if re.SubexpIndex(name) < 0 {
+			return fmt.Errorf("references unknown capture group %q", name)
+		}
+	}
+	return nil
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// lookup returns the claim at path. A claim whose name contains dots (such as a namespaced
+// claim URL) is found by its full name before the path is split into nested objects.
+func lookup(claims map[string]any, path string) (any, bool) {
+	if v, ok := claims[path]; ok {
+		return v, true
+	}
+	var current any = claims
+	for _, part := range strings.Split(path, ".") {
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = obj[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// stringValues returns the scalar values of a claim as strings; arrays yield one value
+// per scalar element
+func stringValues(value any) []string {
+	switch v := value.(type) {
+	case []any:
+		var values []string
+		for _, e := range v {
+			if s, ok := scalarString(e); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		if s, ok := scalarString(v); ok {
+			return []string{s}
+		}
+		return nil
+	}
+}
+
+func scalarString(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
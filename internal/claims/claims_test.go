@@ -0,0 +1,119 @@
+package claims
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
+)
+
+var testMappings = &frkrv1.ClaimMappings{
+	Tenant: []frkrv1.TenantMapping{
+		{Claim: "https://frkr.io/tenant"},
+		{Claim: "org.id", Match: `acme-(?P<env>prod|dev)`, TenantID: "acme-${env}"},
+		{Claim: "email", Match: `.+@example\.com`, TenantID: "example"},
+	},
+	Roles: []frkrv1.RoleMapping{
+		{Group: `frkr-(admin|reader)`, Role: "$1"},
+		{Group: `ops`, Role: "operator", TenantID: "acme-prod"},
+		{Group: `Team (.+)`, Role: "team-$1"},
+	},
+}
+
+func TestEvaluate(t *testing.T) {
+	mapper, err := Compile(testMappings)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantTenant string
+		wantRule   int
+		wantRoles  []string
+	}{
+		{
+			name:       "namespaced claim",
+			token:      `{"https://frkr.io/tenant": "tenant-1", "groups": ["frkr-admin", "frkr-reader"]}`,
+			wantTenant: "tenant-1",
+			wantRule:   0,
+			wantRoles:  []string{"admin", "reader"},
+		},
+		{
+			name:       "nested claim with named capture",
+			token:      `{"org": {"id": "acme-prod"}, "groups": ["ops", "frkr-reader"]}`,
+			wantTenant: "acme-prod",
+			wantRule:   1,
+			wantRoles:  []string{"reader", "operator"},
+		},
+		{
+			name:       "role limited to another tenant",
+			token:      `{"org": {"id": "acme-dev"}, "groups": ["ops"]}`,
+			wantTenant: "acme-dev",
+			wantRule:   1,
+		},
+		{
+			name:       "first matching rule wins",
+			token:      `{"org": {"id": "other"}, "email": "jane@example.com", "groups": "frkr-admin"}`,
+			wantTenant: "example",
+			wantRule:   2,
+			wantRoles:  []string{"admin"},
+		},
+		{
+			name:     "no tenant",
+			token:    `{"email": "jane@elsewhere.com", "groups": ["frkr-admin"]}`,
+			wantRule: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var claims map[string]any
+			if err := json.Unmarshal([]byte(tt.token), &claims); err != nil {
+				t.Fatal(err)
+			}
+			got := mapper.Evaluate(claims)
+			if got.TenantID != tt.wantTenant || got.TenantRule != tt.wantRule {
+				t.Errorf("tenant = %q (rule %d), want %q (rule %d)", got.TenantID, got.TenantRule, tt.wantTenant, tt.wantRule)
+			}
+			if roles := got.RoleNames(); !reflect.DeepEqual(roles, tt.wantRoles) && (len(roles) > 0 || len(tt.wantRoles) > 0) {
+				t.Errorf("roles = %q, want %q", roles, tt.wantRoles)
+			}
+		})
+	}
+}
+
+func TestEvaluate_SkipsInvalidRoleNames(t *testing.T) {
+	mapper, err := Compile(testMappings)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	got := mapper.Evaluate(map[string]any{"https://frkr.io/tenant": "t", "groups": []any{"Team Blue"}})
+	if len(got.Roles) != 0 || len(got.Skipped) != 1 {
+		t.Errorf("roles = %v, skipped = %v; want the role skipped", got.Roles, got.Skipped)
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	_, err := Compile(&frkrv1.ClaimMappings{
+		Tenant: []frkrv1.TenantMapping{
+			{Claim: "org", Match: "(unclosed"},
+			{Claim: "org", Match: "acme-(.+)", TenantID: "$2"},
+		},
+		Roles: []frkrv1.RoleMapping{
+			{Group: "admins", Role: "${name}"},
+			{Group: "", Role: "reader"},
+		},
+	})
+	if err == nil {
+		t.Fatal("Compile() error = nil, want errors")
+	}
+	for _, want := range []string{"tenant[0].match", "tenant[1].tenantId", "roles[0].role", "roles[1].group"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Compile() error = %q, want it to mention %s", err, want)
+		}
+	}
+}
@@ -2,25 +2,49 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
+	"github.com/frkr-io/frkr-operator/internal/claims"
 )
 
 // AuthConfigReconciler reconciles a FrkrAuthConfig object
 type AuthConfigReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// HTTPClient is used to reach OIDC providers (defaults to http.DefaultClient)
+	HTTPClient *http.Client
+	Recorder   record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=frkr.io,resources=frkrauthconfigs,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=frkr.io,resources=frkrauthconfigs/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=frkr.io,resources=frkrauthconfigs/finalizers,verbs=update
-//+kubebuilder:rbac:groups=frkr.io,resources=frkrusers,verbs=list;delete
+//+kubebuilder:rbac:groups=frkr.io,resources=frkrusers,verbs=list;watch;delete
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=frkr.io,resources=frkrinits,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop
 func (r *AuthConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -31,38 +55,233 @@ func (r *AuthConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	// Check if auth type changed
+	// Check if auth type changed; replacing basic auth by oidc deletes the basic auth users
+	// of this namespace, which has to be confirmed first
 	previousType := authConfig.Status.PreviousType
-	if previousType != "" && previousType != authConfig.Spec.Type {
-		// Auth type changed - delete all basic auth users if switching away from basic
-		if previousType == frkrv1.AuthTypeBasic {
-			logger.Info("switching away from basic auth, deleting all basic auth users")
-			var userList frkrv1.FrkrUserList
-			if err := r.List(ctx, &userList); err == nil {
-				for _, user := range userList.Items {
-					if err := r.Delete(ctx, &user); err != nil {
-						logger.Error(err, "failed to delete user", "username", user.Spec.Username)
-					}
-				}
+	if previousType != "" && previousType != authConfig.Spec.Type && removesBasicUsers(previousType, authConfig.Spec.Type) {
+		switched, err := r.switchAwayFromBasic(ctx, &authConfig)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !switched {
+			logger.Info("auth type switch waiting for confirmation", "from", previousType, "to", authConfig.Spec.Type)
+			authConfig.Status.Phase = "PendingConfirmation"
+			if err := r.Status().Update(ctx, &authConfig); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, nil
+		}
+	} else if authConfig.Status.PendingTransition != nil {
+		// The switch was reverted before it was confirmed
+		authConfig.Status.PendingTransition = nil
+		meta.RemoveStatusCondition(&authConfig.Status.Conditions, "SwitchConfirmed")
+	}
+
+	// Resolve the providers in effect; composite configs list them explicitly
+	var result ctrl.Result
+	phase := "Active"
+	providers := authConfig.Spec.EnabledProviders()
+	if authConfig.Spec.Type == frkrv1.AuthTypeComposite {
+		problems := validateCompositeProviders(&authConfig.Spec)
+		setProvidersValidCondition(&authConfig, problems)
+		if len(problems) > 0 {
+			logger.Info("invalid composite auth config", "problems", problems)
+			phase = "Invalid"
+		}
+	} else {
+		meta.RemoveStatusCondition(&authConfig.Status.Conditions, "ProvidersValid")
+	}
+	authConfig.Status.Providers = providers
+
+	if err := r.setPasswordPolicyCondition(ctx, &authConfig); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Claim mappings are applied by the gateways at login; reject rules they cannot evaluate
+	if !validateClaimMappings(&authConfig, providers) {
+		phase = "Invalid"
+	}
+
+	// Validate the OIDC provider; providers can change or expire, so re-validate periodically
+	if slices.Contains(providers, frkrv1.AuthProviderOIDC) {
+		valid, err := r.validateOIDC(ctx, &authConfig)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		now := metav1.NewTime(time.Now())
+		authConfig.Status.LastOIDCValidation = &now
+		result.RequeueAfter = oidcValidationInterval(authConfig.Spec.OIDCConfig)
+		if !valid {
+			if phase == "Active" {
+				phase = "Degraded"
 			}
+			result.RequeueAfter = min(result.RequeueAfter, oidcRetryInterval)
+			logger.Info("OIDC provider validation failed", "issuer", oidcIssuer(&authConfig))
+		}
+	} else {
+		clearOIDCConditions(&authConfig)
+	}
+
+	// Render the configuration for the gateways and roll them; an invalid config keeps the
+	// last good configuration in place
+	if phase != "Invalid" {
+		if err := r.syncGatewayConfig(ctx, &authConfig); err != nil {
+			return ctrl.Result{}, err
+		}
+		if gatewaysRolling(&authConfig) && (result.RequeueAfter == 0 || result.RequeueAfter > gatewayRolloutPollInterval) {
+			result.RequeueAfter = gatewayRolloutPollInterval
 		}
 	}
 
 	// Update status
 	authConfig.Status.PreviousType = authConfig.Spec.Type
-	authConfig.Status.Phase = "Active"
+	authConfig.Status.Phase = phase
 
 	if err := r.Status().Update(ctx, &authConfig); err != nil {
 		return ctrl.Result{}, err
 	}
 
-	logger.Info("reconciled auth config", "type", authConfig.Spec.Type)
-	return ctrl.Result{}, nil
+	logger.Info("reconciled auth config", "type", authConfig.Spec.Type, "phase", phase)
+	return result, nil
+}
+
+// removesBasicUsers reports whether switching the auth type deletes the basic auth users.
+// Composite configs can keep basic auth enabled, so switching to or from composite keeps them.
+func removesBasicUsers(previous, next frkrv1.AuthType) bool {
+	return previous == frkrv1.AuthTypeBasic && next == frkrv1.AuthTypeOIDC
+}
+
+// validateCompositeProviders checks the provider list of a composite config
+func validateCompositeProviders(spec *frkrv1.FrkrAuthConfigSpec) []string {
+	var problems []string
+	seen := map[frkrv1.AuthProviderType]bool{}
+	for _, p := range spec.Providers {
+		switch p.Type {
+		case frkrv1.AuthProviderBasic, frkrv1.AuthProviderOIDC, frkrv1.AuthProviderClientCredentials:
+		default:
+			problems = append(problems, fmt.Sprintf("unknown provider type %q", p.Type))
+			continue
+		}
+		if seen[p.Type] {
+			problems = append(problems, fmt.Sprintf("provider %s is listed more than once", p.Type))
+		}
+		seen[p.Type] = true
+	}
+	enabled := spec.EnabledProviders()
+	if len(enabled) == 0 {
+		problems = append(problems, "at least one provider must be enabled")
+	}
+	if slices.Contains(enabled, frkrv1.AuthProviderOIDC) && spec.OIDCConfig == nil {
+		problems = append(problems, "the oidc provider requires spec.oidcConfig")
+	}
+	return problems
+}
+
+// setProvidersValidCondition reports whether the providers of a composite config are valid
+func setProvidersValidCondition(authConfig *frkrv1.FrkrAuthConfig, problems []string) {
+	if len(problems) > 0 {
+		setCondition(&authConfig.Status.Conditions, "ProvidersValid", false, "InvalidProviders", strings.Join(problems, "; "))
+		return
+	}
+	var names []string
+	for _, p := range authConfig.Spec.EnabledProviders() {
+		names = append(names, string(p))
+	}
+	setCondition(&authConfig.Status.Conditions, "ProvidersValid", true, "ProvidersValid", fmt.Sprintf("Providers in order of precedence: %s", strings.Join(names, ", ")))
+}
+
+// setPasswordPolicyCondition reports whether the config's password policy is the one applied
+// to users in the namespace
+func (r *AuthConfigReconciler) setPasswordPolicyCondition(ctx context.Context, authConfig *frkrv1.FrkrAuthConfig) error {
+	if authConfig.Spec.PasswordPolicy == nil {
+		meta.RemoveStatusCondition(&authConfig.Status.Conditions, "PasswordPolicyActive")
+		return nil
+	}
+	source, err := passwordPolicySource(ctx, r.Client, authConfig.Namespace)
+	if err != nil {
+		return err
+	}
+	if source != nil && source.Name != authConfig.Name {
+		setCondition(&authConfig.Status.Conditions, "PasswordPolicyActive", false, "Superseded",
+			fmt.Sprintf("The password policy of the older FrkrAuthConfig %s applies to this namespace", source.Name))
+		return nil
+	}
+	setCondition(&authConfig.Status.Conditions, "PasswordPolicyActive", true, "Active", "Password policy applies to users in this namespace")
+	return nil
+}
+
+// validateClaimMappings compiles the OIDC claim mappings and reports the result as the
+// ClaimMappingsValid condition
+func validateClaimMappings(authConfig *frkrv1.FrkrAuthConfig, providers []frkrv1.AuthProviderType) bool {
+	cfg := authConfig.Spec.OIDCConfig
+	if cfg == nil || cfg.ClaimMappings == nil || !slices.Contains(providers, frkrv1.AuthProviderOIDC) {
+		meta.RemoveStatusCondition(&authConfig.Status.Conditions, "ClaimMappingsValid")
+		return true
+	}
+	if _, err := claims.Compile(cfg.ClaimMappings); err != nil {
+		setCondition(&authConfig.Status.Conditions, "ClaimMappingsValid", false, "InvalidClaimMappings", strings.ReplaceAll(err.Error(), "\n", "; "))
+		return false
+	}
+	setCondition(&authConfig.Status.Conditions, "ClaimMappingsValid", true, "ClaimMappingsValid",
+		fmt.Sprintf("%d tenant rule(s), %d role rule(s)", len(cfg.ClaimMappings.Tenant), len(cfg.ClaimMappings.Roles)))
+	return true
+}
+
+// authConfigsForUser maps a FrkrUser to the auth configs in its namespace with a pending
+// switch, so the preview of the users to remove stays current
+func (r *AuthConfigReconciler) authConfigsForUser(ctx context.Context, obj client.Object) []reconcile.Request {
+	var list frkrv1.FrkrAuthConfigList
+	if err := r.List(ctx, &list, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list auth configs for user", "user", obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, ac := range list.Items {
+		if ac.Status.PendingTransition != nil {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&ac)})
+		}
+	}
+	return requests
+}
+
+// oidcIssuer returns the configured issuer URL, or "" if there is no OIDC config
+func oidcIssuer(authConfig *frkrv1.FrkrAuthConfig) string {
+	if authConfig.Spec.OIDCConfig == nil {
+		return ""
+	}
+	return authConfig.Spec.OIDCConfig.IssuerURL
+}
+
+// authConfigsForSecret maps a Secret to the auth configs in its namespace that read the
+// OIDC client secret from it
+func (r *AuthConfigReconciler) authConfigsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	var list frkrv1.FrkrAuthConfigList
+	if err := r.List(ctx, &list, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list auth configs for secret", "secret", obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, ac := range list.Items {
+		if cfg := ac.Spec.OIDCConfig; cfg != nil && cfg.ClientSecretRef == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&ac)})
+		}
+	}
+	return requests
 }
 
 // SetupWithManager sets up the controller with the Manager
 func (r *AuthConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&frkrv1.FrkrAuthConfig{}).
+		Owns(&corev1.ConfigMap{}).
+		Watches(&appsv1.Deployment{}, handler.EnqueueRequestsFromMapFunc(r.authConfigsForGateway)).
+		Watches(&frkrv1.FrkrInit{}, handler.EnqueueRequestsFromMapFunc(r.authConfigsInNamespace)).
+		// Which config's password policy applies depends on the other configs in the namespace
+		Watches(&frkrv1.FrkrAuthConfig{}, handler.EnqueueRequestsFromMapFunc(r.authConfigsInNamespace),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.authConfigsForSecret)).
+		Watches(&frkrv1.FrkrUser{}, handler.EnqueueRequestsFromMapFunc(r.authConfigsForUser)).
 		Complete(r)
 }
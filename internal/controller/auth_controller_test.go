@@ -0,0 +1,617 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"sigs.k8s.io/yaml"
+
+	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
+)
+
+var _ = Describe("AuthConfigReconciler", func() {
+	var (
+		ctx        context.Context
+		cancel     context.CancelFunc
+		reconciler *AuthConfigReconciler
+		fakeClient client.Client
+		provider   *httptest.Server
+		recorder   *record.FakeRecorder
+		jwksKeys   []map[string]string
+		req        reconcile.Request
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		scheme := runtime.NewScheme()
+		_ = frkrv1.AddToScheme(scheme)
+		_ = corev1.AddToScheme(scheme)
+		_ = appsv1.AddToScheme(scheme)
+
+		fakeClient = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&frkrv1.FrkrAuthConfig{}).
+			Build()
+
+		recorder = record.NewFakeRecorder(10)
+		jwksKeys = []map[string]string{{"kty": "RSA", "use": "sig", "kid": "key-1"}}
+		mux := http.NewServeMux()
+		provider = httptest.NewServer(mux)
+		mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"issuer":           provider.URL,
+				"jwks_uri":         provider.URL + "/keys",
+				"scopes_supported": []string{"openid", "email", "profile"},
+			})
+		})
+		mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]any{"keys": jwksKeys})
+		})
+
+		reconciler = &AuthConfigReconciler{
+			Client:     fakeClient,
+			Scheme:     scheme,
+			HTTPClient: provider.Client(),
+			Recorder:   recorder,
+		}
+		req = reconcile.Request{NamespacedName: types.NamespacedName{Name: "auth", Namespace: "default"}}
+
+		Expect(fakeClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "oidc-client", Namespace: "default"},
+			Data:       map[string][]byte{"clientSecret": []byte("s3cr3t-value")},
+		})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		provider.Close()
+		cancel()
+	})
+
+	createAuthConfig := func(cfg *frkrv1.OIDCConfig) {
+		Expect(fakeClient.Create(ctx, &frkrv1.FrkrAuthConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "default"},
+			Spec:       frkrv1.FrkrAuthConfigSpec{Type: frkrv1.AuthTypeOIDC, OIDCConfig: cfg},
+		})).To(Succeed())
+	}
+
+	oidcConfig := func() *frkrv1.OIDCConfig {
+		return &frkrv1.OIDCConfig{
+			IssuerURL:       provider.URL,
+			ClientID:        "frkr",
+			ClientSecretRef: "oidc-client",
+			Scopes:          []string{"openid", "email"},
+		}
+	}
+
+	getAuthConfig := func() *frkrv1.FrkrAuthConfig {
+		ac := &frkrv1.FrkrAuthConfig{}
+		Expect(fakeClient.Get(ctx, req.NamespacedName, ac)).To(Succeed())
+		return ac
+	}
+
+	Describe("OIDC validation", func() {
+		It("should activate a valid provider and re-validate periodically", func() {
+			createAuthConfig(oidcConfig())
+
+			result, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(time.Hour))
+
+			ac := getAuthConfig()
+			Expect(ac.Status.Phase).To(Equal("Active"))
+			Expect(ac.Status.LastOIDCValidation).NotTo(BeNil())
+			for _, t := range []string{"OIDCConfigured", "ProviderDiscovered", "JWKSAvailable", "SecretRefResolved", "ScopesSupported"} {
+				Expect(meta.IsStatusConditionTrue(ac.Status.Conditions, t)).To(BeTrue(), t)
+			}
+		})
+
+		It("should report unsupported scopes and a missing secret key", func() {
+			cfg := oidcConfig()
+			cfg.Scopes = append(cfg.Scopes, "groups")
+			cfg.ClientSecretKey = "secret"
+			createAuthConfig(cfg)
+
+			result, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(oidcRetryInterval))
+
+			ac := getAuthConfig()
+			Expect(ac.Status.Phase).To(Equal("Degraded"))
+			scopes := meta.FindStatusCondition(ac.Status.Conditions, "ScopesSupported")
+			Expect(scopes.Status).To(Equal(metav1.ConditionFalse))
+			Expect(scopes.Message).To(ContainSubstring("groups"))
+			secret := meta.FindStatusCondition(ac.Status.Conditions, "SecretRefResolved")
+			Expect(secret.Status).To(Equal(metav1.ConditionFalse))
+			Expect(secret.Message).To(ContainSubstring(`"secret"`))
+		})
+
+		It("should catch a provider whose signing keys disappear", func() {
+			createAuthConfig(oidcConfig())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getAuthConfig().Status.Phase).To(Equal("Active"))
+
+			jwksKeys = nil
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			ac := getAuthConfig()
+			Expect(ac.Status.Phase).To(Equal("Degraded"))
+			Expect(meta.FindStatusCondition(ac.Status.Conditions, "JWKSAvailable").Reason).To(Equal("NoSigningKeys"))
+		})
+
+		It("should report an unreachable issuer", func() {
+			cfg := oidcConfig()
+			cfg.IssuerURL = provider.URL + "/missing"
+			createAuthConfig(cfg)
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			ac := getAuthConfig()
+			Expect(ac.Status.Phase).To(Equal("Degraded"))
+			Expect(meta.FindStatusCondition(ac.Status.Conditions, "ProviderDiscovered").Reason).To(Equal("DiscoveryFailed"))
+			Expect(meta.FindStatusCondition(ac.Status.Conditions, "JWKSAvailable").Status).To(Equal(metav1.ConditionUnknown))
+		})
+
+		It("should map the client secret to the auth configs using it", func() {
+			createAuthConfig(oidcConfig())
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "oidc-client", Namespace: "default"}}
+			Expect(reconciler.authConfigsForSecret(ctx, secret)).To(ConsistOf(req))
+
+			other := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}}
+			Expect(reconciler.authConfigsForSecret(ctx, other)).To(BeEmpty())
+		})
+	})
+
+	Describe("composite mode", func() {
+		disabled := false
+
+		It("should resolve the enabled providers in order and validate oidc", func() {
+			Expect(fakeClient.Create(ctx, &frkrv1.FrkrAuthConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "default"},
+				Spec: frkrv1.FrkrAuthConfigSpec{
+					Type: frkrv1.AuthTypeComposite,
+					Providers: []frkrv1.AuthProvider{
+						{Type: frkrv1.AuthProviderOIDC},
+						{Type: frkrv1.AuthProviderBasic, Enabled: &disabled},
+						{Type: frkrv1.AuthProviderClientCredentials},
+					},
+					OIDCConfig: oidcConfig(),
+				},
+			})).To(Succeed())
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			ac := getAuthConfig()
+			Expect(ac.Status.Phase).To(Equal("Active"))
+			Expect(ac.Status.Providers).To(Equal([]frkrv1.AuthProviderType{frkrv1.AuthProviderOIDC, frkrv1.AuthProviderClientCredentials}))
+			Expect(meta.IsStatusConditionTrue(ac.Status.Conditions, "ProvidersValid")).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(ac.Status.Conditions, "JWKSAvailable")).To(BeTrue())
+		})
+
+		It("should reject a composite config without usable providers", func() {
+			Expect(fakeClient.Create(ctx, &frkrv1.FrkrAuthConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "default"},
+				Spec: frkrv1.FrkrAuthConfigSpec{
+					Type:      frkrv1.AuthTypeComposite,
+					Providers: []frkrv1.AuthProvider{{Type: frkrv1.AuthProviderBasic, Enabled: &disabled}},
+				},
+			})).To(Succeed())
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			ac := getAuthConfig()
+			Expect(ac.Status.Phase).To(Equal("Invalid"))
+			cond := meta.FindStatusCondition(ac.Status.Conditions, "ProvidersValid")
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Message).To(ContainSubstring("at least one provider"))
+		})
+
+		It("should keep basic users when switching between basic and composite", func() {
+			Expect(fakeClient.Create(ctx, &frkrv1.FrkrUser{
+				ObjectMeta: metav1.ObjectMeta{Name: "alice", Namespace: "default"},
+				Spec:       frkrv1.FrkrUserSpec{Username: "alice", TenantID: "tenant-1"},
+			})).To(Succeed())
+			Expect(fakeClient.Create(ctx, &frkrv1.FrkrAuthConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "default"},
+				Spec:       frkrv1.FrkrAuthConfigSpec{Type: frkrv1.AuthTypeBasic},
+			})).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			ac := getAuthConfig()
+			ac.Spec.Type = frkrv1.AuthTypeComposite
+			ac.Spec.Providers = []frkrv1.AuthProvider{{Type: frkrv1.AuthProviderBasic}, {Type: frkrv1.AuthProviderClientCredentials}}
+			Expect(fakeClient.Update(ctx, ac)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			ac = getAuthConfig()
+			ac.Spec.Type = frkrv1.AuthTypeBasic
+			ac.Spec.Providers = nil
+			Expect(fakeClient.Update(ctx, ac)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "alice", Namespace: "default"}, &frkrv1.FrkrUser{})).To(Succeed())
+			Expect(getAuthConfig().Status.Providers).To(Equal([]frkrv1.AuthProviderType{frkrv1.AuthProviderBasic}))
+		})
+	})
+
+	Describe("switching away from basic auth", func() {
+		createUser := func(name, namespace string) {
+			Expect(fakeClient.Create(ctx, &frkrv1.FrkrUser{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Spec:       frkrv1.FrkrUserSpec{Username: name, TenantID: "tenant-1"},
+			})).To(Succeed())
+		}
+
+		switchToOIDC := func() {
+			Expect(fakeClient.Create(ctx, &frkrv1.FrkrAuthConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "default"},
+				Spec:       frkrv1.FrkrAuthConfigSpec{Type: frkrv1.AuthTypeBasic},
+			})).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			ac := getAuthConfig()
+			ac.Spec.Type = frkrv1.AuthTypeOIDC
+			ac.Spec.OIDCConfig = oidcConfig()
+			Expect(fakeClient.Update(ctx, ac)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		confirm := func(token string) {
+			ac := getAuthConfig()
+			ac.Annotations = map[string]string{frkrv1.AuthSwitchConfirmAnnotation: token}
+			Expect(fakeClient.Update(ctx, ac)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		userExists := func(name, namespace string) bool {
+			return fakeClient.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &frkrv1.FrkrUser{}) == nil
+		}
+
+		It("should preview the users to remove and wait for confirmation", func() {
+			createUser("alice", "default")
+			createUser("bob", "other")
+			switchToOIDC()
+
+			ac := getAuthConfig()
+			Expect(ac.Status.Phase).To(Equal("PendingConfirmation"))
+			Expect(ac.Status.PreviousType).To(Equal(frkrv1.AuthTypeBasic))
+			Expect(ac.Status.PendingTransition).NotTo(BeNil())
+			Expect(ac.Status.PendingTransition.Users).To(Equal([]string{"alice"}))
+			Expect(meta.IsStatusConditionFalse(ac.Status.Conditions, "SwitchConfirmed")).To(BeTrue())
+			Expect(recorder.Events).To(Receive(ContainSubstring("ConfirmationRequired")))
+			Expect(userExists("alice", "default")).To(BeTrue())
+
+			confirm("not-the-token")
+			Expect(getAuthConfig().Status.Phase).To(Equal("PendingConfirmation"))
+			Expect(userExists("alice", "default")).To(BeTrue())
+		})
+
+		It("should back up and delete only the namespace's users once confirmed", func() {
+			createUser("alice", "default")
+			createUser("bob", "other")
+			createUser("carol", "default")
+
+			// alice has a generated password; carol brings her own
+			alice := &frkrv1.FrkrUser{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "alice", Namespace: "default"}, alice)).To(Succeed())
+			credentials := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "frkr-user-alice",
+					Namespace:       "default",
+					OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(alice, frkrv1.GroupVersion.WithKind("FrkrUser"))},
+				},
+				Data: map[string][]byte{"username": []byte("alice"), "password": []byte("generated-pw")},
+			}
+			Expect(fakeClient.Create(ctx, credentials)).To(Succeed())
+			alice.Status.CredentialsSecret = credentials.Name
+			Expect(fakeClient.Update(ctx, alice)).To(Succeed())
+
+			carol := &frkrv1.FrkrUser{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "carol", Namespace: "default"}, carol)).To(Succeed())
+			carol.Spec.PasswordSecretRef = &frkrv1.SecretKeyReference{Name: "carol-pw"}
+			Expect(fakeClient.Update(ctx, carol)).To(Succeed())
+
+			switchToOIDC()
+
+			confirm(getAuthConfig().Status.PendingTransition.ConfirmationToken)
+
+			ac := getAuthConfig()
+			Expect(ac.Status.Phase).To(Equal("Active"))
+			Expect(ac.Status.PreviousType).To(Equal(frkrv1.AuthTypeOIDC))
+			Expect(ac.Status.PendingTransition).To(BeNil())
+			Expect(userExists("alice", "default")).To(BeFalse())
+			Expect(userExists("bob", "other")).To(BeTrue())
+
+			backup := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: ac.Status.UserBackupSecret, Namespace: "default"}, backup)).To(Succeed())
+			var list struct {
+				Kind  string                      `json:"kind"`
+				Items []unstructured.Unstructured `json:"items"`
+			}
+			Expect(yaml.Unmarshal(backup.Data[userBackupKey], &list)).To(Succeed())
+			Expect(list.Kind).To(Equal("List"))
+			Expect(list.Items).To(HaveLen(3))
+
+			// The generated password is restored through a Secret the user references
+			Expect(list.Items[0].GetKind()).To(Equal("Secret"))
+			restoredSecret := &corev1.Secret{}
+			Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[0].Object, restoredSecret)).To(Succeed())
+			Expect(restoredSecret.Data["password"]).To(Equal([]byte("generated-pw")))
+			Expect(restoredSecret.OwnerReferences).To(BeEmpty())
+
+			restoredAlice := &frkrv1.FrkrUser{}
+			Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[1].Object, restoredAlice)).To(Succeed())
+			Expect(restoredAlice.Name).To(Equal("alice"))
+			Expect(restoredAlice.Spec.Username).To(Equal("alice"))
+			Expect(restoredAlice.Spec.PasswordSecretRef).To(Equal(&frkrv1.SecretKeyReference{Name: restoredSecret.Name, Key: "password"}))
+
+			restoredCarol := &frkrv1.FrkrUser{}
+			Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[2].Object, restoredCarol)).To(Succeed())
+			Expect(restoredCarol.Spec.PasswordSecretRef).To(Equal(&frkrv1.SecretKeyReference{Name: "carol-pw"}))
+		})
+
+		It("should only accept a confirmation of the recorded preview", func() {
+			createUser("alice", "default")
+			switchToOIDC()
+			token := getAuthConfig().Status.PendingTransition.ConfirmationToken
+
+			// A preview lost from the status has to be shown again before it can be confirmed
+			ac := getAuthConfig()
+			ac.Status.PendingTransition = nil
+			Expect(fakeClient.Status().Update(ctx, ac)).To(Succeed())
+			confirm(token)
+
+			Expect(getAuthConfig().Status.Phase).To(Equal("PendingConfirmation"))
+			Expect(userExists("alice", "default")).To(BeTrue())
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(userExists("alice", "default")).To(BeFalse())
+		})
+
+		It("should require a new confirmation when the affected users change", func() {
+			createUser("alice", "default")
+			switchToOIDC()
+			token := getAuthConfig().Status.PendingTransition.ConfirmationToken
+
+			createUser("carol", "default")
+			confirm(token)
+
+			ac := getAuthConfig()
+			Expect(ac.Status.Phase).To(Equal("PendingConfirmation"))
+			Expect(ac.Status.PendingTransition.Users).To(Equal([]string{"alice", "carol"}))
+			Expect(ac.Status.PendingTransition.ConfirmationToken).NotTo(Equal(token))
+			Expect(userExists("alice", "default")).To(BeTrue())
+		})
+
+		It("should drop the preview when the switch is reverted", func() {
+			createUser("alice", "default")
+			switchToOIDC()
+
+			ac := getAuthConfig()
+			ac.Spec.Type = frkrv1.AuthTypeBasic
+			Expect(fakeClient.Update(ctx, ac)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			ac = getAuthConfig()
+			Expect(ac.Status.Phase).To(Equal("Active"))
+			Expect(ac.Status.PendingTransition).To(BeNil())
+			Expect(meta.FindStatusCondition(ac.Status.Conditions, "SwitchConfirmed")).To(BeNil())
+		})
+	})
+
+	Describe("gateway configuration", func() {
+		var replicas int32 = 2
+
+		BeforeEach(func() {
+			Expect(fakeClient.Create(ctx, &frkrv1.FrkrInit{
+				ObjectMeta: metav1.ObjectMeta{Name: "init", Namespace: "default"},
+				Spec:       frkrv1.FrkrInitSpec{Gateways: []string{"ingest-gateway", "streaming-gateway"}},
+			})).To(Succeed())
+			Expect(fakeClient.Create(ctx, &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "ingest-gateway", Namespace: "default"},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			})).To(Succeed())
+		})
+
+		gatewayHash := func() string {
+			var dep appsv1.Deployment
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "ingest-gateway", Namespace: "default"}, &dep)).To(Succeed())
+			return dep.Spec.Template.Annotations[frkrv1.AuthConfigHashAnnotation]
+		}
+
+		It("should render the config and roll the gateways", func() {
+			createAuthConfig(oidcConfig())
+			result, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(gatewayRolloutPollInterval))
+
+			var cm corev1.ConfigMap
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: frkrv1.GatewayAuthConfigMapName, Namespace: "default"}, &cm)).To(Succeed())
+			var rendered gatewayAuthConfig
+			Expect(json.Unmarshal([]byte(cm.Data[frkrv1.GatewayAuthConfigKey]), &rendered)).To(Succeed())
+			Expect(rendered.Type).To(Equal(frkrv1.AuthTypeOIDC))
+			Expect(rendered.OIDC.Audiences).To(Equal([]string{"frkr"}))
+			Expect(rendered.OIDC.ClientSecretRef).To(Equal(gatewaySecretRef{Name: "oidc-client", Key: "clientSecret"}))
+			Expect(cm.Data[frkrv1.GatewayAuthConfigKey]).NotTo(ContainSubstring("s3cr3t-value"))
+
+			ac := getAuthConfig()
+			Expect(ac.Status.ConfigHash).NotTo(BeEmpty())
+			Expect(cm.Annotations[frkrv1.AuthConfigHashAnnotation]).To(Equal(ac.Status.ConfigHash))
+			Expect(gatewayHash()).To(Equal(ac.Status.ConfigHash))
+			Expect(ac.Status.Gateways).To(ConsistOf(
+				frkrv1.GatewayRolloutStatus{Name: "ingest-gateway", State: "Progressing", Replicas: 2},
+				frkrv1.GatewayRolloutStatus{Name: "streaming-gateway", State: "NotFound"},
+			))
+			Expect(meta.IsStatusConditionFalse(ac.Status.Conditions, "GatewaysRolledOut")).To(BeTrue())
+		})
+
+		It("should report a completed rollout", func() {
+			Expect(fakeClient.Delete(ctx, &frkrv1.FrkrInit{ObjectMeta: metav1.ObjectMeta{Name: "init", Namespace: "default"}})).To(Succeed())
+			Expect(fakeClient.Create(ctx, &frkrv1.FrkrInit{
+				ObjectMeta: metav1.ObjectMeta{Name: "init", Namespace: "default"},
+				Spec:       frkrv1.FrkrInitSpec{Gateways: []string{"ingest-gateway"}},
+			})).To(Succeed())
+			createAuthConfig(oidcConfig())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			var dep appsv1.Deployment
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "ingest-gateway", Namespace: "default"}, &dep)).To(Succeed())
+			dep.Status = appsv1.DeploymentStatus{ObservedGeneration: dep.Generation, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2}
+			Expect(fakeClient.Status().Update(ctx, &dep)).To(Succeed())
+
+			result, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(defaultOIDCValidationInterval))
+			ac := getAuthConfig()
+			Expect(ac.Status.Gateways).To(Equal([]frkrv1.GatewayRolloutStatus{{Name: "ingest-gateway", State: "Complete", UpdatedReplicas: 2, Replicas: 2}}))
+			Expect(meta.IsStatusConditionTrue(ac.Status.Conditions, "GatewaysRolledOut")).To(BeTrue())
+		})
+
+		It("should roll the gateways when the config or the client secret changes", func() {
+			createAuthConfig(oidcConfig())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			first := gatewayHash()
+
+			ac := getAuthConfig()
+			ac.Spec.OIDCConfig.Audiences = []string{"frkr", "frkr-api"}
+			Expect(fakeClient.Update(ctx, ac)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			second := gatewayHash()
+			Expect(second).NotTo(Equal(first))
+
+			Expect(fakeClient.Update(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "oidc-client", Namespace: "default"},
+				Data:       map[string][]byte{"clientSecret": []byte("rotated-value")},
+			})).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gatewayHash()).NotTo(Equal(second))
+			Expect(getAuthConfig().Status.ConfigHash).To(Equal(gatewayHash()))
+		})
+
+		It("should keep the last good config while the config is invalid", func() {
+			createAuthConfig(oidcConfig())
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			hash := gatewayHash()
+
+			ac := getAuthConfig()
+			ac.Spec.Type = frkrv1.AuthTypeComposite
+			ac.Spec.Providers = []frkrv1.AuthProvider{{Type: frkrv1.AuthProviderOIDC}}
+			ac.Spec.OIDCConfig = nil
+			Expect(fakeClient.Update(ctx, ac)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(getAuthConfig().Status.Phase).To(Equal("Invalid"))
+			Expect(gatewayHash()).To(Equal(hash))
+		})
+	})
+
+	Describe("password policy", func() {
+		It("should report which auth config's policy applies", func() {
+			older := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+			Expect(fakeClient.Create(ctx, &frkrv1.FrkrAuthConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "older", Namespace: "default", CreationTimestamp: older},
+				Spec: frkrv1.FrkrAuthConfigSpec{
+					Type:           frkrv1.AuthTypeBasic,
+					PasswordPolicy: &frkrv1.PasswordPolicy{MinLength: 12},
+				},
+			})).To(Succeed())
+			Expect(fakeClient.Create(ctx, &frkrv1.FrkrAuthConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "default", CreationTimestamp: metav1.NewTime(older.Add(time.Minute))},
+				Spec: frkrv1.FrkrAuthConfigSpec{
+					Type:           frkrv1.AuthTypeBasic,
+					PasswordPolicy: &frkrv1.PasswordPolicy{MinLength: 16},
+				},
+			})).To(Succeed())
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			cond := meta.FindStatusCondition(getAuthConfig().Status.Conditions, "PasswordPolicyActive")
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal("Superseded"))
+			Expect(cond.Message).To(ContainSubstring("older"))
+
+			Expect(fakeClient.Delete(ctx, &frkrv1.FrkrAuthConfig{ObjectMeta: metav1.ObjectMeta{Name: "older", Namespace: "default"}})).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(meta.IsStatusConditionTrue(getAuthConfig().Status.Conditions, "PasswordPolicyActive")).To(BeTrue())
+		})
+	})
+
+	Describe("claim mappings", func() {
+		It("should persist the mappings for the gateways", func() {
+			cfg := oidcConfig()
+			cfg.ClaimMappings = &frkrv1.ClaimMappings{
+				Tenant: []frkrv1.TenantMapping{{Claim: "org.id", Match: "acme-(.+)", TenantID: "$1"}},
+				Roles:  []frkrv1.RoleMapping{{Group: "frkr-(admin|reader)", Role: "$1"}},
+			}
+			createAuthConfig(cfg)
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			ac := getAuthConfig()
+			Expect(ac.Status.Phase).To(Equal("Active"))
+			Expect(meta.IsStatusConditionTrue(ac.Status.Conditions, "ClaimMappingsValid")).To(BeTrue())
+
+			var cm corev1.ConfigMap
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: frkrv1.GatewayAuthConfigMapName, Namespace: "default"}, &cm)).To(Succeed())
+			var rendered gatewayAuthConfig
+			Expect(json.Unmarshal([]byte(cm.Data[frkrv1.GatewayAuthConfigKey]), &rendered)).To(Succeed())
+			Expect(rendered.OIDC.ClaimMappings.GroupsClaim).To(Equal("groups"))
+			Expect(rendered.OIDC.ClaimMappings.Tenant).To(Equal(cfg.ClaimMappings.Tenant))
+			Expect(rendered.OIDC.ClaimMappings.Roles).To(Equal(cfg.ClaimMappings.Roles))
+		})
+
+		It("should reject mappings the gateways cannot evaluate", func() {
+			cfg := oidcConfig()
+			cfg.ClaimMappings = &frkrv1.ClaimMappings{
+				Roles: []frkrv1.RoleMapping{{Group: "frkr-admin", Role: "$1"}},
+			}
+			createAuthConfig(cfg)
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			ac := getAuthConfig()
+			Expect(ac.Status.Phase).To(Equal("Invalid"))
+			cond := meta.FindStatusCondition(ac.Status.Conditions, "ClaimMappingsValid")
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Message).To(ContainSubstring("roles[0].role"))
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: frkrv1.GatewayAuthConfigMapName, Namespace: "default"}, &corev1.ConfigMap{})).NotTo(Succeed())
+		})
+	})
+})
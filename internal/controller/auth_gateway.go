@@ -0,0 +1,272 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
+	"github.com/frkr-io/frkr-operator/internal/claims"
+)
+
+// gatewayAuthConfig is the auth configuration rendered for the gateways
+type gatewayAuthConfig struct {
+	Type      frkrv1.AuthType           `json:"type"`
+	Providers []frkrv1.AuthProviderType `json:"providers"`
+	OIDC      *gatewayOIDCConfig        `json:"oidc,omitempty"`
+}
+
+// gatewayOIDCConfig is the OIDC part of the rendered auth configuration
+type gatewayOIDCConfig struct {
+	IssuerURL       string                `json:"issuerUrl"`
+	ClientID        string                `json:"clientId"`
+	Audiences       []string              `json:"audiences"`
+	Scopes          []string              `json:"scopes,omitempty"`
+	ClientSecretRef gatewaySecretRef      `json:"clientSecretRef"`
+	ClaimMappings   *frkrv1.ClaimMappings `json:"claimMappings,omitempty"`
+}
+
+// gatewaySecretRef points the gateways at the Secret holding the OIDC client secret
+type gatewaySecretRef struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// renderGatewayConfig returns the effective auth configuration for the gateways
+func renderGatewayConfig(authConfig *frkrv1.FrkrAuthConfig) gatewayAuthConfig {
+	rendered := gatewayAuthConfig{
+		Type:      authConfig.Spec.Type,
+		Providers: authConfig.Spec.EnabledProviders(),
+	}
+	if cfg := authConfig.Spec.OIDCConfig; cfg != nil && slices.Contains(rendered.Providers, frkrv1.AuthProviderOIDC) {
+		audiences := cfg.Audiences
+		if len(audiences) == 0 {
+			audiences = []string{cfg.ClientID}
+		}
+		key := cfg.ClientSecretKey
+		if key == "" {
+			key = "clientSecret"
+		}
+		rendered.OIDC = &gatewayOIDCConfig{
+			IssuerURL:       cfg.IssuerURL,
+			ClientID:        cfg.ClientID,
+			Audiences:       audiences,
+			Scopes:          cfg.Scopes,
+			ClientSecretRef: gatewaySecretRef{Name: cfg.ClientSecretRef, Key: key},
+		}
+		if cfg.ClaimMappings != nil {
+			mappings := cfg.ClaimMappings.DeepCopy()
+			if mappings.GroupsClaim == "" {
+				mappings.GroupsClaim = claims.DefaultGroupsClaim
+			}
+			rendered.OIDC.ClaimMappings = mappings
+		}
+	}
+	return rendered
+}
+
+// syncGatewayConfig renders the auth configuration into the gateway ConfigMap and rolls the
+// gateway Deployments when it changes. The hash also covers the OIDC client secret, so
+// rotating the secret restarts the gateways too.
+func (r *AuthConfigReconciler) syncGatewayConfig(ctx context.Context, authConfig *frkrv1.FrkrAuthConfig) error {
+	rendered := renderGatewayConfig(authConfig)
+	data, err := json.MarshalIndent(rendered, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode gateway auth config: %w", err)
+	}
+
+	h := sha256.New()
+	h.Write(data)
+	if rendered.OIDC != nil {
+		var secret corev1.Secret
+		err := r.Get(ctx, client.ObjectKey{Name: rendered.OIDC.ClientSecretRef.Name, Namespace: authConfig.Namespace}, &secret)
+		if client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to get secret %s: %w", rendered.OIDC.ClientSecretRef.Name, err)
+		}
+		secretHash := sha256.Sum256(secret.Data[rendered.OIDC.ClientSecretRef.Key])
+		h.Write(secretHash[:])
+	}
+	hash := hex.EncodeToString(h.Sum(nil))
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: frkrv1.GatewayAuthConfigMapName, Namespace: authConfig.Namespace}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		if cm.Annotations == nil {
+			cm.Annotations = map[string]string{}
+		}
+		cm.Annotations[frkrv1.AuthConfigHashAnnotation] = hash
+		cm.Data = map[string]string{frkrv1.GatewayAuthConfigKey: string(data)}
+		return controllerutil.SetControllerReference(authConfig, cm, r.Scheme)
+	}); err != nil {
+		// Only one auth config per namespace can render the gateway configuration
+		var owned *controllerutil.AlreadyOwnedError
+		if errors.As(err, &owned) {
+			setCondition(&authConfig.Status.Conditions, "GatewayConfigRendered", false, "ConfigMapConflict",
+				fmt.Sprintf("ConfigMap %s is managed by %s %s", frkrv1.GatewayAuthConfigMapName, owned.Owner.Kind, owned.Owner.Name))
+			return nil
+		}
+		setCondition(&authConfig.Status.Conditions, "GatewayConfigRendered", false, "RenderFailed", err.Error())
+		return fmt.Errorf("failed to write gateway auth config: %w", err)
+	}
+	authConfig.Status.ConfigHash = hash
+	setCondition(&authConfig.Status.Conditions, "GatewayConfigRendered", true, "Rendered",
+		fmt.Sprintf("Auth configuration written to ConfigMap %s", frkrv1.GatewayAuthConfigMapName))
+
+	return r.rollGateways(ctx, authConfig, hash)
+}
+
+// rollGateways sets the configuration hash on the pod template of every gateway Deployment
+// in the namespace and reports their rollout state
+func (r *AuthConfigReconciler) rollGateways(ctx context.Context, authConfig *frkrv1.FrkrAuthConfig, hash string) error {
+	names, err := r.gatewayNames(ctx, authConfig.Namespace)
+	if err != nil {
+		return err
+	}
+
+	var rollouts []frkrv1.GatewayRolloutStatus
+	for _, name := range names {
+		rollout := frkrv1.GatewayRolloutStatus{Name: name}
+		var dep appsv1.Deployment
+		if err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: authConfig.Namespace}, &dep); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to get gateway %s: %w", name, err)
+			}
+			rollout.State = "NotFound"
+			rollouts = append(rollouts, rollout)
+			continue
+		}
+
+		if dep.Spec.Template.Annotations[frkrv1.AuthConfigHashAnnotation] != hash {
+			patch := client.MergeFrom(dep.DeepCopy())
+			if dep.Spec.Template.Annotations == nil {
+				dep.Spec.Template.Annotations = map[string]string{}
+			}
+			dep.Spec.Template.Annotations[frkrv1.AuthConfigHashAnnotation] = hash
+			if err := r.Patch(ctx, &dep, patch); err != nil {
+				return fmt.Errorf("failed to roll gateway %s: %w", name, err)
+			}
+			log.FromContext(ctx).Info("rolling gateway for auth config change", "gateway", name, "hash", hash)
+		}
+
+		rollout.Replicas = 1
+		if dep.Spec.Replicas != nil {
+			rollout.Replicas = *dep.Spec.Replicas
+		}
+		rollout.UpdatedReplicas = dep.Status.UpdatedReplicas
+		rollout.State = "Progressing"
+		if deploymentRolledOut(&dep) {
+			rollout.State = "Complete"
+		}
+		rollouts = append(rollouts, rollout)
+	}
+	authConfig.Status.Gateways = rollouts
+	setGatewaysRolledOutCondition(authConfig)
+	return nil
+}
+
+// deploymentRolledOut reports whether all replicas of a Deployment run its current pod template
+func deploymentRolledOut(dep *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if dep.Spec.Replicas != nil {
+		replicas = *dep.Spec.Replicas
+	}
+	return dep.Status.ObservedGeneration >= dep.Generation &&
+		dep.Status.UpdatedReplicas == replicas &&
+		dep.Status.Replicas == replicas &&
+		dep.Status.AvailableReplicas == replicas
+}
+
+// gatewayRolloutPollInterval is how often a gateway rollout in progress is checked
+const gatewayRolloutPollInterval = 10 * time.Second
+
+// gatewaysRolling reports whether a gateway rollout is still in progress
+func gatewaysRolling(authConfig *frkrv1.FrkrAuthConfig) bool {
+	for _, g := range authConfig.Status.Gateways {
+		if g.State == "Progressing" {
+			return true
+		}
+	}
+	return false
+}
+
+// setGatewaysRolledOutCondition summarizes the gateway rollouts
+func setGatewaysRolledOutCondition(authConfig *frkrv1.FrkrAuthConfig) {
+	if len(authConfig.Status.Gateways) == 0 {
+		meta.RemoveStatusCondition(&authConfig.Status.Conditions, "GatewaysRolledOut")
+		return
+	}
+	var pending []string
+	for _, g := range authConfig.Status.Gateways {
+		if g.State != "Complete" {
+			pending = append(pending, fmt.Sprintf("%s (%s)", g.Name, g.State))
+		}
+	}
+	if len(pending) > 0 {
+		setCondition(&authConfig.Status.Conditions, "GatewaysRolledOut", false, "RolloutInProgress",
+			fmt.Sprintf("Waiting for gateway(s): %s", strings.Join(pending, ", ")))
+		return
+	}
+	setCondition(&authConfig.Status.Conditions, "GatewaysRolledOut", true, "RolloutComplete",
+		fmt.Sprintf("%d gateway(s) run the current auth configuration", len(authConfig.Status.Gateways)))
+}
+
+// gatewayNames returns the gateway Deployments listed by the FrkrInits in a namespace
+func (r *AuthConfigReconciler) gatewayNames(ctx context.Context, namespace string) ([]string, error) {
+	var inits frkrv1.FrkrInitList
+	if err := r.List(ctx, &inits, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list inits: %w", err)
+	}
+	var names []string
+	for _, init := range inits.Items {
+		for _, name := range init.Spec.Gateways {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names, nil
+}
+
+// authConfigsInNamespace maps an object to the auth configs in its namespace
+func (r *AuthConfigReconciler) authConfigsInNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+	var list frkrv1.FrkrAuthConfigList
+	if err := r.List(ctx, &list, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list auth configs", "namespace", obj.GetNamespace())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, ac := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&ac)})
+	}
+	return requests
+}
+
+// authConfigsForGateway maps a Deployment to the auth configs rolling it out
+func (r *AuthConfigReconciler) authConfigsForGateway(ctx context.Context, obj client.Object) []reconcile.Request {
+	var list frkrv1.FrkrAuthConfigList
+	if err := r.List(ctx, &list, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list auth configs for gateway", "gateway", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, ac := range list.Items {
+		if slices.ContainsFunc(ac.Status.Gateways, func(g frkrv1.GatewayRolloutStatus) bool { return g.Name == obj.GetName() }) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&ac)})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
+	"github.com/frkr-io/frkr-operator/internal/naming"
+)
+
+// authBackupLabel marks user backup Secrets with the auth config that created them
+const authBackupLabel = "frkr.io/auth-config"
+
+// userBackupKey is the Secret key holding the backed up FrkrUsers
+const userBackupKey = "users.yaml"
+
+// userBackupList is a kubectl-applyable list of backed up FrkrUsers and the Secrets
+// holding their generated passwords
+type userBackupList struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Items      []any  `json:"items"`
+}
+
+// switchAwayFromBasic removes the basic auth users in the auth config's namespace once the
+// switch is confirmed. Until then it records the users that would be removed in
+// status.pendingTransition and returns false. Confirmed users are backed up to a Secret
+// before they are deleted.
+func (r *AuthConfigReconciler) switchAwayFromBasic(ctx context.Context, authConfig *frkrv1.FrkrAuthConfig) (bool, error) {
+	logger := log.FromContext(ctx)
+	from, to := authConfig.Status.PreviousType, authConfig.Spec.Type
+
+	var userList frkrv1.FrkrUserList
+	if err := r.List(ctx, &userList, client.InNamespace(authConfig.Namespace)); err != nil {
+		return false, fmt.Errorf("failed to list users: %w", err)
+	}
+	users := userList.Items
+	slices.SortFunc(users, func(a, b frkrv1.FrkrUser) int { return strings.Compare(a.Name, b.Name) })
+	if len(users) == 0 {
+		authConfig.Status.PendingTransition = nil
+		return true, nil
+	}
+
+	names := make([]string, len(users))
+	for i := range users {
+		names[i] = users[i].Name
+	}
+	token := transitionToken(from, to, users)
+
+	// Preview the switch until the annotation confirms the recorded preview, and the preview
+	// still lists exactly the users that would be deleted
+	pending := authConfig.Status.PendingTransition
+	confirmed := pending != nil && pending.ConfirmationToken == token && slices.Equal(pending.Users, names) &&
+		authConfig.Annotations[frkrv1.AuthSwitchConfirmAnnotation] == pending.ConfirmationToken
+	if !confirmed {
+		if pending == nil || pending.ConfirmationToken != token {
+			r.Recorder.Eventf(authConfig, corev1.EventTypeWarning, "ConfirmationRequired",
+				"Switching from %s to %s deletes %d user(s); set annotation %s=%s to proceed",
+				from, to, len(users), frkrv1.AuthSwitchConfirmAnnotation, token)
+		}
+		authConfig.Status.PendingTransition = &frkrv1.AuthTransition{From: from, To: to, Users: names, ConfirmationToken: token}
+		setCondition(&authConfig.Status.Conditions, "SwitchConfirmed", false, "ConfirmationRequired",
+			fmt.Sprintf("Switching from %s to %s deletes %d user(s) in namespace %s; set annotation %s=%s to proceed",
+				from, to, len(users), authConfig.Namespace, frkrv1.AuthSwitchConfirmAnnotation, token))
+		return false, nil
+	}
+
+	backupName, err := r.backupUsers(ctx, authConfig, users, pending.ConfirmationToken)
+	if err != nil {
+		return false, err
+	}
+	authConfig.Status.UserBackupSecret = backupName
+
+	var errs []error
+	for i := range users {
+		if err := r.Delete(ctx, &users[i]); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, fmt.Errorf("failed to delete user %s: %w", users[i].Name, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return false, err
+	}
+
+	logger.Info("removed basic auth users", "from", from, "to", to, "users", len(users), "backup", backupName)
+	r.Recorder.Eventf(authConfig, corev1.EventTypeNormal, "UsersRemoved",
+		"Deleted %d user(s) switching from %s to %s; backup in Secret %s", len(users), from, to, backupName)
+	authConfig.Status.PendingTransition = nil
+	setCondition(&authConfig.Status.Conditions, "SwitchConfirmed", true, "UsersRemoved",
+		fmt.Sprintf("Deleted %d user(s) switching from %s to %s; backup in Secret %s", len(users), from, to, backupName))
+	return true, nil
+}
+
+// backupUsers stores the users in a Secret so they can be restored with kubectl apply.
+// Generated passwords are backed up too: the credentials Secret is deleted with its user,
+// so the backup carries the password in a Secret of its own that the restored user reads
+// through spec.passwordSecretRef. The backup is named after the confirmation token, so
+// retries reuse it.
+func (r *AuthConfigReconciler) backupUsers(ctx context.Context, authConfig *frkrv1.FrkrAuthConfig, users []frkrv1.FrkrUser, token string) (string, error) {
+	backup := userBackupList{APIVersion: "v1", Kind: "List"}
+	for _, u := range users {
+		restored := frkrv1.FrkrUser{
+			TypeMeta: metav1.TypeMeta{APIVersion: frkrv1.GroupVersion.String(), Kind: "FrkrUser"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        u.Name,
+				Namespace:   u.Namespace,
+				Labels:      u.Labels,
+				Annotations: u.Annotations,
+			},
+			Spec: u.Spec,
+		}
+
+		password, err := r.generatedPassword(ctx, &u)
+		if err != nil {
+			return "", err
+		}
+		if password != nil {
+			passwordSecret := corev1.Secret{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+				ObjectMeta: metav1.ObjectMeta{Name: naming.DNSName(u.Name, "restored-password"), Namespace: u.Namespace},
+				Data:       map[string][]byte{"password": password},
+			}
+			restored.Spec.PasswordSecretRef = &frkrv1.SecretKeyReference{Name: passwordSecret.Name, Key: "password"}
+			backup.Items = append(backup.Items, passwordSecret)
+		}
+		backup.Items = append(backup.Items, restored)
+	}
+	data, err := yaml.Marshal(backup)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode user backup: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-users-backup-%s", authConfig.Name, token)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: authConfig.Namespace,
+			Labels:    map[string]string{authBackupLabel: authConfig.Name},
+		},
+		Data: map[string][]byte{userBackupKey: data},
+	}
+	if err := r.Create(ctx, secret); err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", fmt.Errorf("failed to create user backup secret %s: %w", name, err)
+	}
+	return name, nil
+}
+
+// generatedPassword returns the password the operator generated for a user, read from its
+// credentials Secret; nil if the user sets its own password or has no credentials Secret
+func (r *AuthConfigReconciler) generatedPassword(ctx context.Context, user *frkrv1.FrkrUser) ([]byte, error) {
+	if user.Spec.PasswordSecretRef != nil || user.Spec.Password != "" || user.Status.CredentialsSecret == "" {
+		return nil, nil
+	}
+	var secret corev1.Secret
+	if err := r.Get(ctx, client.ObjectKey{Name: user.Status.CredentialsSecret, Namespace: user.Namespace}, &secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get credentials secret %s: %w", user.Status.CredentialsSecret, err)
+	}
+	if !metav1.IsControlledBy(&secret, user) || len(secret.Data["password"]) == 0 {
+		return nil, nil
+	}
+	return secret.Data["password"], nil
+}
+
+// transitionToken identifies a switch and the exact users it removes
+func transitionToken(from, to frkrv1.AuthType, users []frkrv1.FrkrUser) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s>%s", from, to)
+	for _, u := range users {
+		fmt.Fprintf(h, "|%s/%s", u.Name, u.UID)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
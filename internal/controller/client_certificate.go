@@ -0,0 +1,214 @@
+package controller
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
+	"github.com/frkr-io/frkr-operator/internal/pki"
+)
+
+// defaultClientCertificateDuration is how long certificates issued to mtls clients are valid
+const defaultClientCertificateDuration = 90 * 24 * time.Hour
+
+// defaultClientCertificateRenewBefore is how long before expiry a client certificate is renewed
+const defaultClientCertificateRenewBefore = 30 * 24 * time.Hour
+
+// DefaultClientCASecretName is the Secret holding the internal client CA unless CLIENT_CA_SECRET is set
+const DefaultClientCASecretName = "frkr-client-ca"
+
+// ClientCASecretFromEnv returns the location of the internal client CA Secret from
+// CLIENT_CA_SECRET (<namespace>/<name>), defaulting to frkr-client-ca in the operator's
+// namespace (POD_NAMESPACE, or frkr-system)
+func ClientCASecretFromEnv() (types.NamespacedName, error) {
+	namespace := os.Getenv("POD_NAMESPACE")
+	if namespace == "" {
+		namespace = "frkr-system"
+	}
+	value := os.Getenv("CLIENT_CA_SECRET")
+	if value == "" {
+		return types.NamespacedName{Namespace: namespace, Name: DefaultClientCASecretName}, nil
+	}
+	ns, name, ok := strings.Cut(value, "/")
+	if !ok || ns == "" || name == "" {
+		return types.NamespacedName{}, fmt.Errorf("invalid CLIENT_CA_SECRET %q (expected <namespace>/<name>)", value)
+	}
+	return types.NamespacedName{Namespace: ns, Name: name}, nil
+}
+
+// Secret keys holding a newly issued certificate until the database and the client Secret
+// have been updated, so a retry resumes it instead of issuing another
+const (
+	pendingTLSCertKey = "pendingTLSCert"
+	pendingTLSKeyKey  = "pendingTLSKey"
+)
+
+// clientCertificate is the certificate of an mtls client after reconciliation
+type clientCertificate struct {
+	CertPEM []byte
+	KeyPEM  []byte
+	CAPEM   []byte
+	Cert    *x509.Certificate
+	RenewAt time.Time
+
+	// Issued is true if a new certificate was issued in this reconcile
+	Issued bool
+	// Staged is true if the certificate was staged by an earlier, interrupted reconcile
+	Staged bool
+	// Renewed is true if the new certificate replaces an earlier one
+	Renewed bool
+}
+
+// ensureClientCertificate returns the certificate to store in the client Secret. A new one
+// is issued when there is none, renewal is requested or due, or the CA or subject changed;
+// a certificate staged by an interrupted reconcile is resumed instead. Certificates never
+// outlive the credential's expiresAt, and an expired credential gets no new certificate:
+// the current one is kept, or nil is returned if there is none.
+func (r *ClientReconciler) ensureClientCertificate(ctx context.Context, crd *frkrv1.FrkrClient, existing *corev1.Secret, renewRequested bool, expiresAt *metav1.Time, now time.Time) (*clientCertificate, error) {
+	ca, err := pki.LoadOrCreateCA(ctx, r.Client, r.CASecret)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := defaultClientCertificateDuration
+	renewBefore := defaultClientCertificateRenewBefore
+	if spec := crd.Spec.Certificate; spec != nil {
+		if spec.Duration != nil {
+			duration = spec.Duration.Duration
+		}
+		if spec.RenewBefore != nil {
+			renewBefore = spec.RenewBefore.Duration
+		}
+	}
+
+	current, _ := pki.ParseCertificate(existing.Data[corev1.TLSCertKey])
+	keyPEM := existing.Data[corev1.TLSPrivateKeyKey]
+	if expiresAt != nil && !now.Before(expiresAt.Time) {
+		if current == nil || len(keyPEM) == 0 {
+			return nil, nil
+		}
+		return &clientCertificate{
+			CertPEM: existing.Data[corev1.TLSCertKey],
+			KeyPEM:  keyPEM,
+			CAPEM:   ca.CertPEM,
+			Cert:    current,
+			RenewAt: certificateRenewAt(current, renewBefore),
+		}, nil
+	}
+
+	staged, _ := pki.ParseCertificate(existing.Data[pendingTLSCertKey])
+	stagedKeyPEM := existing.Data[pendingTLSKeyKey]
+	if staged != nil && len(stagedKeyPEM) > 0 && ca.IssuedBy(staged) && certificateMatches(staged, crd) && now.Before(staged.NotAfter) {
+		return &clientCertificate{
+			CertPEM: existing.Data[pendingTLSCertKey],
+			KeyPEM:  stagedKeyPEM,
+			CAPEM:   ca.CertPEM,
+			Cert:    staged,
+			RenewAt: certificateRenewAt(staged, renewBefore),
+			Issued:  true,
+			Staged:  true,
+			Renewed: current != nil,
+		}, nil
+	}
+
+	if current != nil && len(keyPEM) > 0 && ca.IssuedBy(current) && certificateMatches(current, crd) {
+		renewAt := certificateRenewAt(current, renewBefore)
+		// A certificate that already runs until the credential expires is not renewed
+		atExpiry := expiresAt != nil && !current.NotAfter.Before(expiresAt.Time)
+		if !renewRequested && (now.Before(renewAt) || atExpiry) {
+			return &clientCertificate{
+				CertPEM: existing.Data[corev1.TLSCertKey],
+				KeyPEM:  keyPEM,
+				CAPEM:   ca.CertPEM,
+				Cert:    current,
+				RenewAt: renewAt,
+			}, nil
+		}
+	}
+
+	notAfter := now.Add(duration)
+	if expiresAt != nil && expiresAt.Time.Before(notAfter) {
+		notAfter = expiresAt.Time
+	}
+	certPEM, newKeyPEM, cert, err := ca.IssueClientCertificate(crd.Spec.TenantID, crd.Spec.ClientID, notAfter)
+	if err != nil {
+		return nil, err
+	}
+	return &clientCertificate{
+		CertPEM: certPEM,
+		KeyPEM:  newKeyPEM,
+		CAPEM:   ca.CertPEM,
+		Cert:    cert,
+		RenewAt: certificateRenewAt(cert, renewBefore),
+		Issued:  true,
+		Renewed: current != nil,
+	}, nil
+}
+
+// setCertificateIssuedCondition reports whether an mtls client holds a certificate
+func setCertificateIssuedCondition(crd *frkrv1.FrkrClient, cert *clientCertificate, expired bool) {
+	if crd.Spec.CredentialType != frkrv1.ClientCredentialMTLS {
+		meta.RemoveStatusCondition(&crd.Status.Conditions, "CertificateIssued")
+		return
+	}
+	switch {
+	case cert == nil:
+		meta.SetStatusCondition(&crd.Status.Conditions, metav1.Condition{
+			Type:    "CertificateIssued",
+			Status:  metav1.ConditionFalse,
+			Reason:  "CredentialExpired",
+			Message: "No certificate is issued for an expired credential",
+		})
+	case expired:
+		meta.SetStatusCondition(&crd.Status.Conditions, metav1.Condition{
+			Type:    "CertificateIssued",
+			Status:  metav1.ConditionFalse,
+			Reason:  "CredentialExpired",
+			Message: "The credential expired; its certificate is no longer renewed",
+		})
+	default:
+		meta.SetStatusCondition(&crd.Status.Conditions, metav1.Condition{
+			Type:    "CertificateIssued",
+			Status:  metav1.ConditionTrue,
+			Reason:  "Issued",
+			Message: fmt.Sprintf("Certificate valid until %s", cert.Cert.NotAfter.UTC().Format(time.RFC3339)),
+		})
+	}
+}
+
+// certificateMatches reports whether a certificate was issued for the client's tenant and ID
+func certificateMatches(cert *x509.Certificate, crd *frkrv1.FrkrClient) bool {
+	return cert.Subject.CommonName == crd.Spec.ClientID &&
+		len(cert.Subject.Organization) == 1 && cert.Subject.Organization[0] == crd.Spec.TenantID
+}
+
+// certificateRenewAt returns when a certificate is due for renewal. If renewBefore exceeds
+// the certificate's lifetime, it is renewed after two thirds of its lifetime instead.
+func certificateRenewAt(cert *x509.Certificate, renewBefore time.Duration) time.Time {
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+	if renewBefore >= lifetime {
+		return cert.NotBefore.Add(lifetime * 2 / 3)
+	}
+	return cert.NotAfter.Add(-renewBefore)
+}
+
+// certificateStatus describes an issued certificate for the FrkrClient status
+func certificateStatus(cc *clientCertificate) *frkrv1.ClientCertificateStatus {
+	return &frkrv1.ClientCertificateStatus{
+		Subject:      cc.Cert.Subject.String(),
+		Fingerprint:  pki.Fingerprint(cc.Cert),
+		SerialNumber: cc.Cert.SerialNumber.Text(16),
+		NotBefore:    metav1.NewTime(cc.Cert.NotBefore),
+		NotAfter:     metav1.NewTime(cc.Cert.NotAfter),
+		RenewAt:      metav1.NewTime(cc.RenewAt),
+	}
+}
@@ -4,30 +4,63 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/frkr-io/frkr-common/util"
 	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
 	"github.com/frkr-io/frkr-operator/internal/infra"
+	"github.com/frkr-io/frkr-operator/internal/pki"
 )
 
+// defaultClientRotationGracePeriod is how long the previous secret stays valid after a rotation
+const defaultClientRotationGracePeriod = 24 * time.Hour
+
+// clientRotationRequestAnnotation records on the client Secret the rotation request its
+// pending or current secret belongs to
+const clientRotationRequestAnnotation = "frkr.io/rotation-request"
+
+// pendingClientSecretKey holds a rotated secret in the client Secret until the rotation is
+// complete
+const pendingClientSecretKey = "pendingClientSecret"
+
+// defaultClientExpiryWarning is how long before expiry the ExpiringSoon condition is raised
+const defaultClientExpiryWarning = 72 * time.Hour
+
+// clientFinalizer guards revocation of the database credential when a FrkrClient is deleted
+const clientFinalizer = "frkr.io/client-cleanup"
+
 // ClientReconciler reconciles a FrkrClient object
 type ClientReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
-	DB     *infra.DB
+	Scheme     *runtime.Scheme
+	DB         *infra.DB
+	KafkaAdmin *infra.KafkaAdmin
+	Recorder   record.EventRecorder
+
+	// CASecret is the Secret holding the internal CA that issues mtls client certificates
+	CASecret types.NamespacedName
 }
 
 //+kubebuilder:rbac:groups=frkr.io,resources=frkrclients,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=frkr.io,resources=frkrclients/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=frkr.io,resources=frkrclients/finalizers,verbs=update
+//+kubebuilder:rbac:groups=frkr.io,resources=frkrstreams,verbs=get;list;watch
+//+kubebuilder:rbac:groups=frkr.io,resources=frkrtenants,verbs=get;list;watch
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 func (r *ClientReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
@@ -37,21 +70,138 @@ func (r *ClientReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	// Secret handling
+	if !crd.DeletionTimestamp.IsZero() {
+		return r.finalizeClient(ctx, &crd)
+	}
+	if controllerutil.AddFinalizer(&crd, clientFinalizer) {
+		if err := r.Update(ctx, &crd); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to add finalizer: %w", err)
+		}
+	}
+
+	// Look up the Kubernetes Secret holding the secrets currently in use
+	secretName := fmt.Sprintf("frkr-client-%s", crd.Name)
+	var existingSecret corev1.Secret
+	if err := r.Get(ctx, client.ObjectKey{Name: secretName, Namespace: crd.Namespace}, &existingSecret); client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to check for existing secret: %w", err)
+	}
+	currentSecret := string(existingSecret.Data["clientSecret"])
+
+	// Secret handling: a referenced Secret wins over the deprecated inline field
 	clientSecret := crd.Spec.Secret
-	if clientSecret == "" {
-		// Check if a secret already exists in Kubernetes for this client.
-		// If not, auto-generate a new one and ensure it persists in a K8s Secret.
+	setInlineCredentialCondition(&crd.Status.Conditions, "secret", "secretRef", crd.Spec.Secret != "")
+	if ref := crd.Spec.SecretRef; ref != nil {
+		value, problem, err := readSecretRef(ctx, r.Client, crd.Namespace, ref, "clientSecret")
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		setSecretRefCondition(&crd.Status.Conditions, ref, problem)
+		if problem != "" {
+			// The Secret watch triggers a new reconcile once the Secret is fixed
+			log.Info("waiting for client secret", "clientId", crd.Spec.ClientID, "reason", problem)
+			if crd.Status.ID == "" {
+				crd.Status.Phase = "Pending"
+			}
+			if err := r.Status().Update(ctx, &crd); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, nil
+		}
+		clientSecret = value
+	} else {
+		setSecretRefCondition(&crd.Status.Conditions, nil, "")
+	}
+
+	// Resolve the referenced stream; the FrkrStream watch triggers a new reconcile once it is Ready
+	streamID := crd.Spec.StreamID
+	if crd.Spec.StreamRef != "" {
+		resolved, problem, err := r.resolveStreamRef(ctx, &crd)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		setStreamNotReadyCondition(&crd, problem)
+		if problem != "" {
+			log.Info("waiting for stream", "clientId", crd.Spec.ClientID, "reason", problem)
+			if crd.Status.ID == "" {
+				crd.Status.Phase = "Pending"
+			}
+			if err := r.Status().Update(ctx, &crd); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, nil
+		}
+		streamID = resolved
+	} else {
+		setStreamNotReadyCondition(&crd, "")
+	}
+	crd.Status.StreamID = streamID
 
-		secretName := fmt.Sprintf("frkr-client-%s", crd.Name)
-		var existingSecret corev1.Secret
-		err := r.Get(ctx, client.ObjectKey{Name: secretName, Namespace: crd.Namespace}, &existingSecret)
-		if err == nil {
-			// Found existing secret, check if it has the data
-			if val, ok := existingSecret.Data["clientSecret"]; ok {
-				clientSecret = string(val)
+	// Resolve the streams the client is scoped to. Without all of them the client would be
+	// stored with fewer scopes, which the gateways read as access to every stream, so it
+	// waits like a client whose streamRef is not Ready and an existing credential is suspended.
+	scopes, unresolvedScopes, err := r.resolveScopes(ctx, &crd)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	setScopesResolvedCondition(&crd, unresolvedScopes)
+	if len(unresolvedScopes) > 0 {
+		log.Info("waiting for scoped streams", "clientId", crd.Spec.ClientID, "unresolved", unresolvedScopes)
+		// Suspend a credential stored earlier, even if the status lost its ID
+		if r.DB != nil {
+			if err := r.DB.SuspendClient(crd.Spec.TenantID, crd.Spec.ClientID); err != nil {
+				log.Error(err, "failed to suspend client in db")
+				return ctrl.Result{}, err
 			}
 		}
+		crd.Status.Phase = "Pending"
+		if err := r.Status().Update(ctx, &crd); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Validate the network restrictions and rate limit; an invalid policy keeps the one in effect
+	policy, policyProblems := resolveClientPolicy(&crd)
+	setPolicyValidCondition(&crd, policyProblems)
+	if len(policyProblems) > 0 {
+		log.Info("rejecting invalid client policy", "clientId", crd.Spec.ClientID, "problems", policyProblems)
+		r.Recorder.Eventf(&crd, corev1.EventTypeWarning, "InvalidPolicy", "Client policy rejected: %s", strings.Join(policyProblems, "; "))
+		if crd.Status.ID == "" {
+			crd.Status.Phase = "Rejected"
+		}
+		if err := r.Status().Update(ctx, &crd); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// A rotation is pending while the annotation holds a value that has not been applied yet
+	now := time.Now()
+	rotationRequest := crd.Annotations[frkrv1.ClientRotateSecretAnnotation]
+	rotationRequested := rotationRequest != "" && rotationRequest != crd.Status.ObservedRotationRequest
+	rotated := false
+	mtls := crd.Spec.CredentialType == frkrv1.ClientCredentialMTLS
+
+	// A rotation is staged in the Secret before the database is changed. A staged secret or
+	// certificate is resumed after an interrupted reconcile, and a request the Secret has
+	// already completed is only recorded, so a retry never rotates twice.
+	stagedSecret := ""
+	rotationCompleted := false
+	if rotationRequested && existingSecret.Annotations[clientRotationRequestAnnotation] == rotationRequest {
+		stagedSecret = string(existingSecret.Data[pendingClientSecretKey])
+		rotationCompleted = stagedSecret == "" && len(existingSecret.Data[pendingTLSCertKey]) == 0
+	}
+
+	if mtls {
+		// Certificate clients have no shared secret; a rotation request renews the certificate
+	} else if clientSecret == "" {
+		// Reuse the secret already stored in Kubernetes unless a rotation is requested.
+		// If there is none, auto-generate a new one and ensure it persists in a K8s Secret.
+		clientSecret = currentSecret
+		if rotationRequested && !rotationCompleted && clientSecret != "" {
+			clientSecret = stagedSecret
+			rotated = true
+		}
 
 		if clientSecret == "" {
 			var err error
@@ -61,30 +211,148 @@ func (r *ClientReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 			}
 			crd.Status.SecretGenerated = true
 		}
+	} else if rotationRequested {
+		r.Recorder.Event(&crd, corev1.EventTypeWarning, "RotationSkipped", "The client secret is supplied by spec.secret or spec.secretRef; rotate it there")
+	}
+
+	// Keep the previous secret valid for the grace period after a rotation
+	previousSecret := string(existingSecret.Data["previousClientSecret"])
+	previousExpiresAt := crd.Status.PreviousSecretExpiresAt
+	if rotated {
+		grace := defaultClientRotationGracePeriod
+		if crd.Spec.RotationGracePeriod != nil {
+			grace = crd.Spec.RotationGracePeriod.Duration
+		}
+		previousSecret = currentSecret
+		expiresAt := metav1.NewTime(now.Add(grace))
+		previousExpiresAt = &expiresAt
+	}
+	retirePrevious := previousExpiresAt != nil && !now.Before(previousExpiresAt.Time)
+	if retirePrevious {
+		previousSecret = ""
+		previousExpiresAt = nil
+	}
+
+	// Resolve when the credential expires
+	expiresAt := clientExpiry(&crd)
+	expiryChanged := crd.Status.ID == "" || !expiresAt.Equal(crd.Status.ExpiresAt)
+	expired := expiresAt != nil && !now.Before(expiresAt.Time)
+	wasExpired := crd.Status.Phase == "Expired"
+
+	// Issue or renew the client certificate
+	var cert *clientCertificate
+	if mtls {
+		cert, err = r.ensureClientCertificate(ctx, &crd, &existingSecret, rotationRequested && !rotationCompleted, expiresAt, now)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to issue client certificate: %w", err)
+		}
+	}
+	setCertificateIssuedCondition(&crd, cert, expired)
+
+	// Stage a newly issued certificate so a retry after a failure below registers the same one
+	if cert != nil && cert.Issued && !cert.Staged {
+		if existingSecret.Annotations == nil {
+			existingSecret.Annotations = map[string]string{}
+		}
+		if existingSecret.Data == nil {
+			existingSecret.Data = map[string][]byte{}
+		}
+		if rotationRequested {
+			existingSecret.Annotations[clientRotationRequestAnnotation] = rotationRequest
+		}
+		existingSecret.Data[pendingTLSCertKey] = cert.CertPEM
+		existingSecret.Data[pendingTLSKeyKey] = cert.KeyPEM
+		if existingSecret.Name == "" {
+			existingSecret.Name = secretName
+			existingSecret.Namespace = req.Namespace
+			if err := ctrl.SetControllerReference(&crd, &existingSecret, r.Scheme); err != nil {
+				return ctrl.Result{}, err
+			}
+			if err := r.Create(ctx, &existingSecret); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to stage client certificate: %w", err)
+			}
+		} else if err := r.Update(ctx, &existingSecret); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to stage client certificate: %w", err)
+		}
+	}
+
+	// Stage the new secret so a retry after a failure below rotates to the same secret
+	if rotated && stagedSecret == "" {
+		if existingSecret.Annotations == nil {
+			existingSecret.Annotations = map[string]string{}
+		}
+		existingSecret.Annotations[clientRotationRequestAnnotation] = rotationRequest
+		existingSecret.Data[pendingClientSecretKey] = []byte(clientSecret)
+		if err := r.Update(ctx, &existingSecret); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to stage rotated secret: %w", err)
+		}
 	}
 
 	// Persist to DB
 	if r.DB != nil {
-		// Resolve StreamID.
-		// Currently, we expect the Spec.StreamID to be a valid UUID if provided.
-		// The infra layer handles the database interaction to ensure the client exists.
+		var dbStreamID *string
+		if streamID != "" {
+			dbStreamID = &streamID
+		}
 
-		var streamID *string
-		if crd.Spec.StreamID != "" {
-			s := crd.Spec.StreamID
-			streamID = &s
+		// Rotate before ensuring the client so the current secret is kept as the previous one;
+		// a staged secret the database already holds is not rotated again
+		if rotated {
+			if err := r.DB.RotateClientSecret(crd.Spec.TenantID, crd.Spec.ClientID, clientSecret, previousExpiresAt.Time); err != nil {
+				log.Error(err, "failed to rotate client secret in db")
+				return ctrl.Result{}, err
+			}
+		}
+		if retirePrevious {
+			if err := r.DB.RetirePreviousClientSecret(crd.Spec.TenantID, crd.Spec.ClientID); err != nil {
+				log.Error(err, "failed to retire previous client secret in db")
+				return ctrl.Result{}, err
+			}
 		}
 
-		dbClient, err := r.DB.EnsureClient(crd.Spec.TenantID, crd.Spec.ClientID, clientSecret, streamID)
-		if err != nil {
-			if strings.Contains(err.Error(), "does not exist") {
-				// Dependency missing (Tenant/Stream)
-				log.Error(err, "dependency missing")
-				return ctrl.Result{RequeueAfter: 30}, err
+		var dbScopes []infra.ClientScope
+		for _, scope := range scopes {
+			dbScope := infra.ClientScope{StreamID: scope.StreamID}
+			for _, perm := range scope.Permissions {
+				dbScope.Permissions = append(dbScope.Permissions, string(perm))
 			}
+			dbScopes = append(dbScopes, dbScope)
+		}
+
+		// The credentials Secret is written after the database, so a secret it already holds
+		// is stored; only a new or changed secret is written (and hashed) again
+		replaceSecret := !rotated && (crd.Status.ID == "" || clientSecret != currentSecret)
+		dbClient, err := r.DB.EnsureClient(crd.Spec.TenantID, crd.Spec.ClientID, clientSecret, replaceSecret, dbStreamID, dbScopes)
+		if err != nil {
 			log.Error(err, "failed to ensure client in db")
 			return ctrl.Result{}, err
 		}
+		if cert != nil {
+			if err := r.DB.RegisterClientCertificate(crd.Spec.TenantID, crd.Spec.ClientID, pki.Fingerprint(cert.Cert), cert.Cert.Subject.String(), cert.Cert.NotAfter); err != nil {
+				log.Error(err, "failed to register client certificate in db")
+				return ctrl.Result{}, err
+			}
+		}
+
+		// Persist the expiry so gateways reject expired credentials
+		if expiryChanged {
+			var until *time.Time
+			if expiresAt != nil {
+				until = &expiresAt.Time
+			}
+			if err := r.DB.SetClientExpiry(crd.Spec.TenantID, crd.Spec.ClientID, until); err != nil {
+				log.Error(err, "failed to set client expiry in db")
+				return ctrl.Result{}, err
+			}
+		}
+
+		// Persist the network restrictions and rate limit so gateways can enforce them
+		if crd.Status.ID == "" || !policy.matchesStatus(&crd.Status) {
+			if err := r.DB.SetClientPolicy(crd.Spec.TenantID, crd.Spec.ClientID, policy.AllowedCIDRs, policy.dbRateLimit()); err != nil {
+				log.Error(err, "failed to set client policy in db")
+				return ctrl.Result{}, err
+			}
+		}
 
 		crd.Status.ID = dbClient.ID
 		crd.Status.Phase = "Ready"
@@ -93,44 +361,372 @@ func (r *ClientReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 	// Create/Update Kubernetes Secret
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("frkr-client-%s", crd.Name),
+			Name:      secretName,
 			Namespace: req.Namespace,
 		},
 		Data: map[string][]byte{
-			"clientId":     []byte(crd.Spec.ClientID),
-			"clientSecret": []byte(clientSecret),
+			"clientId": []byte(crd.Spec.ClientID),
 		},
 	}
+	if cert != nil {
+		secret.Data[corev1.TLSCertKey] = cert.CertPEM
+		secret.Data[corev1.TLSPrivateKeyKey] = cert.KeyPEM
+		secret.Data["ca.crt"] = cert.CAPEM
+	} else if !mtls {
+		secret.Data["clientSecret"] = []byte(clientSecret)
+	}
+	if previousSecret != "" {
+		secret.Data["previousClientSecret"] = []byte(previousSecret)
+	}
 	if err := ctrl.SetControllerReference(&crd, secret, r.Scheme); err != nil {
 		return ctrl.Result{}, err
 	}
 
 	// Apply Secret
 	// ... (simplified apply)
-	existingSecret := &corev1.Secret{}
-	err := r.Get(ctx, client.ObjectKeyFromObject(secret), existingSecret)
-	if err != nil && client.IgnoreNotFound(err) == nil {
+	if existingSecret.Name == "" {
 		if err := r.Create(ctx, secret); err != nil {
 			return ctrl.Result{}, err
 		}
-	} else if err == nil {
+	} else {
 		existingSecret.Data = secret.Data
-		if err := r.Update(ctx, existingSecret); err != nil {
+		if err := r.Update(ctx, &existingSecret); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
 
+	// Replicate the credentials into consumer namespaces
+	deliveries, err := r.syncDeliveries(ctx, &crd, secret.Data)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	crd.Status.Deliveries = deliveries
+	setDeliveredCondition(&crd)
+
+	// Record the rotation
+	if rotationRequested {
+		crd.Status.ObservedRotationRequest = rotationRequest
+	}
+	if rotated {
+		rotatedAt := metav1.NewTime(now)
+		crd.Status.LastRotation = &rotatedAt
+		r.Recorder.Eventf(&crd, corev1.EventTypeNormal, "SecretRotated", "Client secret rotated; the previous secret stays valid until %s", previousExpiresAt.UTC().Format(time.RFC3339))
+	}
+	if retirePrevious {
+		r.Recorder.Event(&crd, corev1.EventTypeNormal, "PreviousSecretRetired", "The previous client secret is no longer accepted")
+	}
+	crd.Status.PreviousSecretExpiresAt = previousExpiresAt
+
+	// Record the certificate
+	crd.Status.Certificate = nil
+	if cert != nil {
+		crd.Status.Certificate = certificateStatus(cert)
+		if cert.Renewed {
+			renewedAt := metav1.NewTime(now)
+			crd.Status.LastRotation = &renewedAt
+			r.Recorder.Eventf(&crd, corev1.EventTypeNormal, "CertificateRenewed", "Client certificate renewed; valid until %s", cert.Cert.NotAfter.UTC().Format(time.RFC3339))
+		} else if cert.Issued {
+			r.Recorder.Eventf(&crd, corev1.EventTypeNormal, "CertificateIssued", "Client certificate issued; valid until %s", cert.Cert.NotAfter.UTC().Format(time.RFC3339))
+		}
+	}
+
+	// Record the expiry and warn ahead of it
+	if expired {
+		if !wasExpired {
+			r.Recorder.Eventf(&crd, corev1.EventTypeWarning, "CredentialExpired", "Client credential %s expired at %s", crd.Spec.ClientID, expiresAt.UTC().Format(time.RFC3339))
+		}
+		crd.Status.Phase = "Expired"
+	} else if wasExpired {
+		crd.Status.Phase = "Ready"
+	}
+	crd.Status.ExpiresAt = expiresAt
+	crd.Status.Scopes = scopes
+	crd.Status.AllowedCIDRs = policy.AllowedCIDRs
+	crd.Status.RateLimit = policy.RateLimit
+	requeueAfter := r.updateExpiryStatus(&crd, expiresAt, now)
+
 	// Update Status
 	if err := r.Status().Update(ctx, &crd); err != nil {
 		return ctrl.Result{}, err
 	}
 
+	if expired && crd.Spec.DeleteOnExpiry {
+		log.Info("deleting expired client", "clientId", crd.Spec.ClientID)
+		if err := r.Delete(ctx, &crd); client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to delete expired client: %w", err)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Retire the previous secret once the grace period is over
+	if previousExpiresAt != nil && (requeueAfter == 0 || previousExpiresAt.Sub(now) < requeueAfter) {
+		requeueAfter = previousExpiresAt.Sub(now)
+	}
+	// Renew the certificate when it is due
+	if cert != nil && !expired && (requeueAfter == 0 || cert.RenewAt.Sub(now) < requeueAfter) {
+		requeueAfter = cert.RenewAt.Sub(now)
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// finalizeClient revokes the database credential, removes the client's consumer group from
+// the broker and deletes delivered Secrets before letting Kubernetes delete the FrkrClient
+// and its owned secret
+func (r *ClientReconciler) finalizeClient(ctx context.Context, crd *frkrv1.FrkrClient) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if !controllerutil.ContainsFinalizer(crd, clientFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if r.DB != nil {
+		if err := r.DB.RevokeClient(crd.Spec.TenantID, crd.Spec.ClientID); err != nil {
+			logger.Error(err, "failed to revoke client credential")
+			r.Recorder.Eventf(crd, corev1.EventTypeWarning, "RevokeFailed", "Failed to revoke client credential: %v", err)
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Consumers use their client ID as consumer group, so drop its committed offsets too
+	if r.KafkaAdmin != nil {
+		if err := r.KafkaAdmin.DeleteConsumerGroups(ctx, crd.Spec.ClientID); err != nil {
+			logger.Error(err, "failed to remove consumer group")
+			r.Recorder.Eventf(crd, corev1.EventTypeWarning, "RevokeFailed", "Failed to remove consumer group: %v", err)
+			return ctrl.Result{}, err
+		}
+	}
+	if err := r.removeDeliveries(ctx, crd, nil); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.Recorder.Eventf(crd, corev1.EventTypeNormal, "CredentialRevoked", "Client credential %s revoked", crd.Spec.ClientID)
+
+	controllerutil.RemoveFinalizer(crd, clientFinalizer)
+	if err := r.Update(ctx, crd); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to remove finalizer: %w", err)
+	}
+
+	logger.Info("finalized client", "clientId", crd.Spec.ClientID)
 	return ctrl.Result{}, nil
 }
 
+// resolveStreamRef returns the database ID of the FrkrStream named by spec.streamRef. If the
+// stream is missing or not Ready yet, it returns a description of the problem instead.
+func (r *ClientReconciler) resolveStreamRef(ctx context.Context, crd *frkrv1.FrkrClient) (streamID, problem string, err error) {
+	var stream frkrv1.FrkrStream
+	if err := r.Get(ctx, client.ObjectKey{Name: crd.Spec.StreamRef, Namespace: crd.Namespace}, &stream); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return "", "", fmt.Errorf("failed to get stream %s: %w", crd.Spec.StreamRef, err)
+		}
+		return "", fmt.Sprintf("FrkrStream %s not found", crd.Spec.StreamRef), nil
+	}
+	if stream.Status.StreamID == "" {
+		return "", fmt.Sprintf("FrkrStream %s is not Ready yet", crd.Spec.StreamRef), nil
+	}
+	return stream.Status.StreamID, "", nil
+}
+
+// setStreamNotReadyCondition reports whether the stream named by spec.streamRef could be resolved
+func setStreamNotReadyCondition(crd *frkrv1.FrkrClient, problem string) {
+	if crd.Spec.StreamRef == "" {
+		meta.RemoveStatusCondition(&crd.Status.Conditions, "StreamNotReady")
+		return
+	}
+	if problem != "" {
+		meta.SetStatusCondition(&crd.Status.Conditions, metav1.Condition{
+			Type:    "StreamNotReady",
+			Status:  metav1.ConditionTrue,
+			Reason:  "WaitingForStream",
+			Message: problem,
+		})
+		return
+	}
+	meta.SetStatusCondition(&crd.Status.Conditions, metav1.Condition{
+		Type:    "StreamNotReady",
+		Status:  metav1.ConditionFalse,
+		Reason:  "StreamResolved",
+		Message: fmt.Sprintf("Scoped to FrkrStream %s", crd.Spec.StreamRef),
+	})
+}
+
+// resolveScopes looks up the FrkrStreams referenced by the client's scopes.
+// Streams that do not exist or have no stream ID yet are returned as unresolved.
+func (r *ClientReconciler) resolveScopes(ctx context.Context, crd *frkrv1.FrkrClient) ([]frkrv1.ClientScopeStatus, []string, error) {
+	var resolved []frkrv1.ClientScopeStatus
+	var unresolved []string
+
+	for _, scope := range crd.Spec.Scopes {
+		var stream frkrv1.FrkrStream
+		if err := r.Get(ctx, client.ObjectKey{Name: scope.StreamRef, Namespace: crd.Namespace}, &stream); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return nil, nil, fmt.Errorf("failed to get stream %s: %w", scope.StreamRef, err)
+			}
+			unresolved = append(unresolved, fmt.Sprintf("%s (not found)", scope.StreamRef))
+			continue
+		}
+		if stream.Status.StreamID == "" {
+			unresolved = append(unresolved, fmt.Sprintf("%s (not ready)", scope.StreamRef))
+			continue
+		}
+		resolved = append(resolved, frkrv1.ClientScopeStatus{
+			StreamRef:   scope.StreamRef,
+			StreamID:    stream.Status.StreamID,
+			Permissions: scope.Permissions,
+		})
+	}
+	return resolved, unresolved, nil
+}
+
+// setScopesResolvedCondition reports whether every scoped stream could be resolved
+func setScopesResolvedCondition(crd *frkrv1.FrkrClient, unresolved []string) {
+	if len(crd.Spec.Scopes) == 0 {
+		meta.RemoveStatusCondition(&crd.Status.Conditions, "ScopesResolved")
+		return
+	}
+	if len(unresolved) > 0 {
+		meta.SetStatusCondition(&crd.Status.Conditions, metav1.Condition{
+			Type:    "ScopesResolved",
+			Status:  metav1.ConditionFalse,
+			Reason:  "StreamNotFound",
+			Message: fmt.Sprintf("Client suspended until every scoped stream is Ready: %s", strings.Join(unresolved, ", ")),
+		})
+		return
+	}
+	meta.SetStatusCondition(&crd.Status.Conditions, metav1.Condition{
+		Type:    "ScopesResolved",
+		Status:  metav1.ConditionTrue,
+		Reason:  "ScopesResolved",
+		Message: fmt.Sprintf("%d stream scope(s) applied", len(crd.Spec.Scopes)),
+	})
+}
+
+// clientsForStream maps a FrkrStream to the clients in its namespace that reference it
+func (r *ClientReconciler) clientsForStream(ctx context.Context, obj client.Object) []reconcile.Request {
+	var clientList frkrv1.FrkrClientList
+	if err := r.List(ctx, &clientList, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list clients for stream", "stream", obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, c := range clientList.Items {
+		if c.Spec.StreamRef == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&c)})
+			continue
+		}
+		for _, scope := range c.Spec.Scopes {
+			if scope.StreamRef == obj.GetName() {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&c)})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+// clientExpiry returns when the credential expires from spec.expiresAt and spec.ttl, or nil if it does not
+func clientExpiry(crd *frkrv1.FrkrClient) *metav1.Time {
+	var expiresAt *metav1.Time
+	if crd.Spec.ExpiresAt != nil {
+		expiresAt = crd.Spec.ExpiresAt.DeepCopy()
+	}
+	if crd.Spec.TTL != nil && !crd.CreationTimestamp.IsZero() {
+		ttlExpiry := metav1.NewTime(crd.CreationTimestamp.Add(crd.Spec.TTL.Duration))
+		if expiresAt == nil || ttlExpiry.Before(expiresAt) {
+			expiresAt = &ttlExpiry
+		}
+	}
+	return expiresAt
+}
+
+// updateExpiryStatus sets the ExpiringSoon condition and returns how long to wait
+// until the next warning or expiry
+func (r *ClientReconciler) updateExpiryStatus(crd *frkrv1.FrkrClient, expiresAt *metav1.Time, now time.Time) time.Duration {
+	if expiresAt == nil {
+		meta.RemoveStatusCondition(&crd.Status.Conditions, "ExpiringSoon")
+		return 0
+	}
+	if !now.Before(expiresAt.Time) {
+		meta.SetStatusCondition(&crd.Status.Conditions, metav1.Condition{
+			Type:    "ExpiringSoon",
+			Status:  metav1.ConditionFalse,
+			Reason:  "Expired",
+			Message: fmt.Sprintf("Credential expired at %s", expiresAt.UTC().Format(time.RFC3339)),
+		})
+		return 0
+	}
+
+	warning := defaultClientExpiryWarning
+	if crd.Spec.ExpiryWarning != nil {
+		warning = crd.Spec.ExpiryWarning.Duration
+	}
+	warnAt := expiresAt.Add(-warning)
+	msg := fmt.Sprintf("Credential expires at %s", expiresAt.UTC().Format(time.RFC3339))
+	if now.Before(warnAt) {
+		meta.SetStatusCondition(&crd.Status.Conditions, metav1.Condition{
+			Type:    "ExpiringSoon",
+			Status:  metav1.ConditionFalse,
+			Reason:  "CredentialValid",
+			Message: msg,
+		})
+		return warnAt.Sub(now)
+	}
+
+	// Warn once when the condition becomes true
+	if !meta.IsStatusConditionTrue(crd.Status.Conditions, "ExpiringSoon") {
+		r.Recorder.Event(crd, corev1.EventTypeWarning, "ExpiringSoon", msg)
+	}
+	meta.SetStatusCondition(&crd.Status.Conditions, metav1.Condition{
+		Type:    "ExpiringSoon",
+		Status:  metav1.ConditionTrue,
+		Reason:  "ExpiryApproaching",
+		Message: msg,
+	})
+	return expiresAt.Sub(now)
+}
+
+// clientSecretRefField indexes FrkrClients by the name of the Secret in spec.secretRef
+const clientSecretRefField = ".spec.secretRef.name"
+
+// indexClientSecretRef returns the index values of clientSecretRefField
+func indexClientSecretRef(obj client.Object) []string {
+	c, ok := obj.(*frkrv1.FrkrClient)
+	if !ok || c.Spec.SecretRef == nil {
+		return nil
+	}
+	return []string{c.Spec.SecretRef.Name}
+}
+
+// clientsForSecret maps a Secret to the clients in its namespace whose secret it holds,
+// or to the client that delivered it
+func (r *ClientReconciler) clientsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	// Delivered Secrets are restored if they are changed or deleted
+	if name, ns := obj.GetLabels()[deliveryClientNameLabel], obj.GetLabels()[deliveryClientNamespaceLabel]; name != "" && ns != "" {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name, Namespace: ns}}}
+	}
+
+	var clientList frkrv1.FrkrClientList
+	if err := r.List(ctx, &clientList, client.InNamespace(obj.GetNamespace()),
+		client.MatchingFields{clientSecretRefField: obj.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list clients for secret", "secret", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(clientList.Items))
+	for _, c := range clientList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&c)})
+	}
+	return requests
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ClientReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &frkrv1.FrkrClient{}, clientSecretRefField, indexClientSecretRef); err != nil {
+		return fmt.Errorf("failed to index clients by secret: %w", err)
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&frkrv1.FrkrClient{}).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.clientsForSecret)).
+		Watches(&frkrv1.FrkrStream{}, handler.EnqueueRequestsFromMapFunc(r.clientsForStream)).
+		Watches(&frkrv1.FrkrTenant{}, handler.EnqueueRequestsFromMapFunc(r.clientsForTenant)).
 		Complete(r)
 }
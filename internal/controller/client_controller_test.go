@@ -0,0 +1,805 @@
+package controller
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
+	"github.com/frkr-io/frkr-operator/internal/pki"
+)
+
+var _ = Describe("ClientReconciler", func() {
+	var (
+		ctx        context.Context
+		cancel     context.CancelFunc
+		reconciler *ClientReconciler
+		fakeClient client.Client
+		recorder   *record.FakeRecorder
+		req        reconcile.Request
+		secretKey  types.NamespacedName
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		scheme := runtime.NewScheme()
+		_ = frkrv1.AddToScheme(scheme)
+		_ = corev1.AddToScheme(scheme)
+
+		fakeClient = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&frkrv1.FrkrClient{}, &frkrv1.FrkrStream{}).
+			WithIndex(&frkrv1.FrkrClient{}, clientSecretRefField, indexClientSecretRef).
+			Build()
+
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &ClientReconciler{
+			Client:   fakeClient,
+			Scheme:   scheme,
+			Recorder: recorder,
+			CASecret: types.NamespacedName{Name: DefaultClientCASecretName, Namespace: "default"},
+		}
+
+		req = reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      "orders-client",
+				Namespace: "default",
+			},
+		}
+		secretKey = types.NamespacedName{Name: "frkr-client-orders-client", Namespace: "default"}
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	createClient := func(spec frkrv1.FrkrClientSpec) {
+		spec.TenantID = "tenant-1"
+		spec.ClientID = "orders"
+		Expect(fakeClient.Create(ctx, &frkrv1.FrkrClient{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "orders-client",
+				Namespace: "default",
+			},
+			Spec: spec,
+		})).To(Succeed())
+	}
+
+	requestRotation := func(value string) {
+		crd := &frkrv1.FrkrClient{}
+		Expect(fakeClient.Get(ctx, req.NamespacedName, crd)).To(Succeed())
+		crd.Annotations = map[string]string{frkrv1.ClientRotateSecretAnnotation: value}
+		Expect(fakeClient.Update(ctx, crd)).To(Succeed())
+	}
+
+	Describe("credential expiry", func() {
+		It("should warn ahead of expiry and requeue at the deadline", func() {
+			expiresAt := metav1.NewTime(time.Now().Add(time.Hour))
+			createClient(frkrv1.FrkrClientSpec{ExpiresAt: &expiresAt})
+
+			result, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+
+			updated := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.ExpiresAt).NotTo(BeNil())
+			Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, "ExpiringSoon")).To(BeTrue())
+			Expect(recorder.Events).To(Receive(ContainSubstring("ExpiringSoon")))
+		})
+
+		It("should mark an expired credential as Expired", func() {
+			expiresAt := metav1.NewTime(time.Now().Add(-time.Minute))
+			createClient(frkrv1.FrkrClientSpec{ExpiresAt: &expiresAt})
+
+			result, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeZero())
+
+			updated := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Phase).To(Equal("Expired"))
+			Expect(recorder.Events).To(Receive(ContainSubstring("CredentialExpired")))
+		})
+
+		It("should delete an expired client when requested", func() {
+			expiresAt := metav1.NewTime(time.Now().Add(-time.Minute))
+			createClient(frkrv1.FrkrClientSpec{ExpiresAt: &expiresAt, DeleteOnExpiry: true})
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			// The finalizer revokes the credential on the next reconcile
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = fakeClient.Get(ctx, req.NamespacedName, &frkrv1.FrkrClient{})
+			Expect(client.IgnoreNotFound(err)).To(Succeed())
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should use the earlier of expiresAt and the TTL", func() {
+			created := time.Now().Add(-time.Hour)
+			late := metav1.NewTime(created.Add(48 * time.Hour))
+			crd := &frkrv1.FrkrClient{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+				Spec: frkrv1.FrkrClientSpec{
+					ExpiresAt: &late,
+					TTL:       &metav1.Duration{Duration: 24 * time.Hour},
+				},
+			}
+			Expect(clientExpiry(crd).Time).To(BeTemporally("~", created.Add(24*time.Hour), time.Second))
+
+			crd.Spec.TTL = nil
+			Expect(clientExpiry(crd).Time).To(BeTemporally("~", late.Time, time.Second))
+		})
+	})
+
+	Describe("deletion", func() {
+		It("should add a finalizer and revoke the credential on deletion", func() {
+			createClient(frkrv1.FrkrClientSpec{})
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			crd := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, crd)).To(Succeed())
+			Expect(crd.Finalizers).To(ContainElement(clientFinalizer))
+
+			Expect(fakeClient.Delete(ctx, crd)).To(Succeed())
+			Expect(fakeClient.Get(ctx, req.NamespacedName, crd)).To(Succeed())
+			Expect(crd.DeletionTimestamp).NotTo(BeNil())
+
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = fakeClient.Get(ctx, req.NamespacedName, &frkrv1.FrkrClient{})
+			Expect(client.IgnoreNotFound(err)).To(Succeed())
+			Expect(err).To(HaveOccurred())
+			Expect(recorder.Events).To(Receive(ContainSubstring("CredentialRevoked")))
+		})
+	})
+
+	Describe("secret rotation", func() {
+		It("should generate a secret without a previous one", func() {
+			createClient(frkrv1.FrkrClientSpec{})
+
+			result, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeZero())
+
+			secret := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, secretKey, secret)).To(Succeed())
+			Expect(secret.Data["clientSecret"]).NotTo(BeEmpty())
+			Expect(secret.Data).NotTo(HaveKey("previousClientSecret"))
+		})
+
+		It("should keep the previous secret valid for the grace period", func() {
+			createClient(frkrv1.FrkrClientSpec{RotationGracePeriod: &metav1.Duration{Duration: time.Hour}})
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			before := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, secretKey, before)).To(Succeed())
+
+			requestRotation("1")
+			result, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+
+			after := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, secretKey, after)).To(Succeed())
+			Expect(after.Data["clientSecret"]).NotTo(Equal(before.Data["clientSecret"]))
+			Expect(after.Data["previousClientSecret"]).To(Equal(before.Data["clientSecret"]))
+
+			updated := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.ObservedRotationRequest).To(Equal("1"))
+			Expect(updated.Status.LastRotation).NotTo(BeNil())
+			Expect(updated.Status.PreviousSecretExpiresAt).NotTo(BeNil())
+			Expect(recorder.Events).To(Receive(ContainSubstring("SecretRotated")))
+
+			// A rotation request is only applied once
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			again := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, secretKey, again)).To(Succeed())
+			Expect(again.Data["clientSecret"]).To(Equal(after.Data["clientSecret"]))
+		})
+
+		It("should retire the previous secret when the grace period is over", func() {
+			createClient(frkrv1.FrkrClientSpec{})
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			requestRotation("1")
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			crd := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, crd)).To(Succeed())
+			expired := metav1.NewTime(time.Now().Add(-time.Minute))
+			crd.Status.PreviousSecretExpiresAt = &expired
+			Expect(fakeClient.Status().Update(ctx, crd)).To(Succeed())
+
+			result, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeZero())
+
+			secret := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, secretKey, secret)).To(Succeed())
+			Expect(secret.Data).NotTo(HaveKey("previousClientSecret"))
+
+			updated := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.PreviousSecretExpiresAt).To(BeNil())
+		})
+
+		It("should resume an interrupted rotation without rotating twice", func() {
+			createClient(frkrv1.FrkrClientSpec{})
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			before := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, secretKey, before)).To(Succeed())
+
+			stored := string(before.Data["clientSecret"])
+			failEnsure := true
+			db, fakeSQL := newFakeDB(func(query string, args []driver.NamedValue) (*fakeRows, error) {
+				switch {
+				case strings.Contains(query, "SELECT client_secret FROM clients"):
+					return &fakeRows{Columns: []string{"client_secret"}, Rows: [][]driver.Value{{stored}}}, nil
+				case strings.Contains(query, "SET previous_client_secret = client_secret"):
+					stored = args[1].Value.(string)
+				case strings.Contains(query, "INSERT INTO clients"):
+					if failEnsure {
+						return nil, errors.New("connection reset")
+					}
+					return clientRow("client-uuid", "tenant-1", "orders", stored), nil
+				}
+				return nil, nil
+			})
+			reconciler.DB = db
+
+			// The database is rotated, then the reconcile fails before the Secret is updated
+			requestRotation("1")
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).To(HaveOccurred())
+			Expect(fakeSQL.Executed("SET previous_client_secret = client_secret")).To(HaveLen(1))
+
+			staged := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, secretKey, staged)).To(Succeed())
+			Expect(staged.Data["clientSecret"]).To(Equal(before.Data["clientSecret"]))
+			Expect(staged.Data[pendingClientSecretKey]).NotTo(BeEmpty())
+			Expect(staged.Annotations[clientRotationRequestAnnotation]).To(Equal("1"))
+
+			// The retry finishes the staged rotation instead of generating another secret
+			failEnsure = false
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeSQL.Executed("SET previous_client_secret = client_secret")).To(HaveLen(1))
+
+			after := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, secretKey, after)).To(Succeed())
+			Expect(after.Data["clientSecret"]).To(Equal(staged.Data[pendingClientSecretKey]))
+			Expect(after.Data["previousClientSecret"]).To(Equal(before.Data["clientSecret"]))
+			Expect(after.Data).NotTo(HaveKey(pendingClientSecretKey))
+
+			// A request the Secret has completed is only recorded if the status update was lost
+			updated := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.ObservedRotationRequest).To(Equal("1"))
+			updated.Status.ObservedRotationRequest = ""
+			Expect(fakeClient.Status().Update(ctx, updated)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeSQL.Executed("SET previous_client_secret = client_secret")).To(HaveLen(1))
+
+			again := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, secretKey, again)).To(Succeed())
+			Expect(again.Data["clientSecret"]).To(Equal(after.Data["clientSecret"]))
+			Expect(again.Data["previousClientSecret"]).To(Equal(before.Data["clientSecret"]))
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.ObservedRotationRequest).To(Equal("1"))
+		})
+
+		It("should not rotate a supplied secret", func() {
+			createClient(frkrv1.FrkrClientSpec{Secret: "supplied-secret"})
+			requestRotation("1")
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			secret := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, secretKey, secret)).To(Succeed())
+			Expect(string(secret.Data["clientSecret"])).To(Equal("supplied-secret"))
+			Expect(recorder.Events).To(Receive(ContainSubstring("RotationSkipped")))
+		})
+	})
+
+	Describe("stream scopes", func() {
+		createStream := func(name, streamID string) {
+			stream := &frkrv1.FrkrStream{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Spec:       frkrv1.FrkrStreamSpec{TenantID: "tenant-1", Name: name},
+			}
+			Expect(fakeClient.Create(ctx, stream)).To(Succeed())
+			if streamID != "" {
+				stream.Status.Phase = "Ready"
+				stream.Status.StreamID = streamID
+				Expect(fakeClient.Status().Update(ctx, stream)).To(Succeed())
+			}
+		}
+
+		It("should resolve every scoped stream", func() {
+			createStream("orders", "stream-orders")
+			createStream("payments", "stream-payments")
+			createClient(frkrv1.FrkrClientSpec{Scopes: []frkrv1.ClientScope{
+				{StreamRef: "orders", Permissions: []frkrv1.StreamPermission{frkrv1.StreamPermissionRead}},
+				{StreamRef: "payments", Permissions: []frkrv1.StreamPermission{frkrv1.StreamPermissionRead, frkrv1.StreamPermissionWrite}},
+			}})
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			updated := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Scopes).To(HaveLen(2))
+			Expect(updated.Status.Scopes[0].StreamID).To(Equal("stream-orders"))
+			Expect(updated.Status.Scopes[1].StreamID).To(Equal("stream-payments"))
+			Expect(updated.Status.Scopes[1].Permissions).To(ConsistOf(frkrv1.StreamPermissionRead, frkrv1.StreamPermissionWrite))
+			Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, "ScopesResolved")).To(BeTrue())
+		})
+
+		It("should wait for every scoped stream without storing the client", func() {
+			db, fakeSQL := newFakeDB(nil)
+			reconciler.DB = db
+			createStream("orders", "stream-orders")
+			createStream("pending", "")
+			createClient(frkrv1.FrkrClientSpec{Scopes: []frkrv1.ClientScope{
+				{StreamRef: "orders", Permissions: []frkrv1.StreamPermission{frkrv1.StreamPermissionRead}},
+				{StreamRef: "pending", Permissions: []frkrv1.StreamPermission{frkrv1.StreamPermissionRead}},
+				{StreamRef: "missing", Permissions: []frkrv1.StreamPermission{frkrv1.StreamPermissionWrite}},
+			}})
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeSQL.Executed("INSERT INTO clients")).To(BeEmpty())
+			Expect(fakeSQL.Executed("INSERT INTO client_scopes")).To(BeEmpty())
+
+			updated := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Phase).To(Equal("Pending"))
+			Expect(updated.Status.Scopes).To(BeEmpty())
+			cond := meta.FindStatusCondition(updated.Status.Conditions, "ScopesResolved")
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Message).To(ContainSubstring("pending (not ready)"))
+			Expect(cond.Message).To(ContainSubstring("missing (not found)"))
+			Expect(fakeClient.Get(ctx, secretKey, &corev1.Secret{})).NotTo(Succeed())
+		})
+
+		It("should store the secret in plaintext and only write it again when it changes", func() {
+			var stored string
+			db, fakeSQL := newFakeDB(func(query string, args []driver.NamedValue) (*fakeRows, error) {
+				switch {
+				case strings.Contains(query, "INSERT INTO clients"):
+					for _, a := range args {
+						if v, ok := a.Value.(string); ok && len(v) >= 8 && v != "tenant-1" && v != "orders" {
+							stored = v
+						}
+					}
+					return clientRow("client-uuid", "tenant-1", "orders", stored), nil
+				case strings.Contains(query, "FROM clients") && stored != "":
+					return clientRow("client-uuid", "tenant-1", "orders", stored), nil
+				}
+				return nil, nil
+			})
+			reconciler.DB = db
+			createClient(frkrv1.FrkrClientSpec{Secret: "first-secret"})
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored).To(Equal("first-secret"))
+
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeSQL.Executed("INSERT INTO clients")).To(HaveLen(1))
+			Expect(fakeSQL.Executed("SET client_secret")).To(BeEmpty())
+
+			crd := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, crd)).To(Succeed())
+			crd.Spec.Secret = "second-secret"
+			Expect(fakeClient.Update(ctx, crd)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeSQL.Executed("SET client_secret")).To(HaveLen(1))
+		})
+
+		It("should suspend a stored client while a scoped stream is missing", func() {
+			db, fakeSQL := newFakeDB(func(query string, _ []driver.NamedValue) (*fakeRows, error) {
+				if strings.Contains(query, "INSERT INTO clients") {
+					return clientRow("client-uuid", "tenant-1", "orders", "$2a$10$stored"), nil
+				}
+				return nil, nil
+			})
+			reconciler.DB = db
+			createStream("orders", "stream-orders")
+			createClient(frkrv1.FrkrClientSpec{Scopes: []frkrv1.ClientScope{
+				{StreamRef: "orders", Permissions: []frkrv1.StreamPermission{frkrv1.StreamPermissionRead}},
+			}})
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeSQL.Executed("INSERT INTO clients")).To(HaveLen(1))
+
+			Expect(fakeClient.Delete(ctx, &frkrv1.FrkrStream{ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "default"}})).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeSQL.Executed("INSERT INTO clients")).To(HaveLen(1))
+			Expect(fakeSQL.Executed("SET disabled = true")).To(HaveLen(1))
+
+			updated := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Phase).To(Equal("Pending"))
+			Expect(meta.IsStatusConditionFalse(updated.Status.Conditions, "ScopesResolved")).To(BeTrue())
+
+			// The suspension is lifted once the scopes are stored again
+			createStream("orders", "stream-orders")
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeSQL.Executed("SET disabled = false")).NotTo(BeEmpty())
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Phase).To(Equal("Ready"))
+		})
+
+		It("should wait for a referenced stream to become Ready", func() {
+			createClient(frkrv1.FrkrClientSpec{StreamRef: "orders"})
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			updated := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Phase).To(Equal("Pending"))
+			Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, "StreamNotReady")).To(BeTrue())
+			Expect(fakeClient.Get(ctx, secretKey, &corev1.Secret{})).NotTo(Succeed())
+
+			createStream("orders", "stream-orders")
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.StreamID).To(Equal("stream-orders"))
+			Expect(meta.IsStatusConditionFalse(updated.Status.Conditions, "StreamNotReady")).To(BeTrue())
+			Expect(fakeClient.Get(ctx, secretKey, &corev1.Secret{})).To(Succeed())
+		})
+
+		It("should enqueue clients that reference a stream", func() {
+			createClient(frkrv1.FrkrClientSpec{Scopes: []frkrv1.ClientScope{
+				{StreamRef: "orders", Permissions: []frkrv1.StreamPermission{frkrv1.StreamPermissionRead}},
+			}})
+
+			stream := &frkrv1.FrkrStream{ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "default"}}
+			Expect(reconciler.clientsForStream(ctx, stream)).To(ConsistOf(req))
+
+			other := &frkrv1.FrkrStream{ObjectMeta: metav1.ObjectMeta{Name: "payments", Namespace: "default"}}
+			Expect(reconciler.clientsForStream(ctx, other)).To(BeEmpty())
+
+			crd := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, crd)).To(Succeed())
+			crd.Spec.Scopes = nil
+			crd.Spec.StreamRef = "payments"
+			Expect(fakeClient.Update(ctx, crd)).To(Succeed())
+			Expect(reconciler.clientsForStream(ctx, other)).To(ConsistOf(req))
+		})
+	})
+
+	Describe("credential delivery", func() {
+		BeforeEach(func() {
+			Expect(fakeClient.Create(ctx, &frkrv1.FrkrTenant{
+				ObjectMeta: metav1.ObjectMeta{Name: "tenant-1", Namespace: "default"},
+				Spec:       frkrv1.FrkrTenantSpec{AllowedDeliveryNamespaces: []string{"team-*"}},
+			})).To(Succeed())
+		})
+
+		deliveredKey := types.NamespacedName{Name: "orders-creds", Namespace: "team-a"}
+
+		It("should deliver credentials into allowed namespaces only", func() {
+			createClient(frkrv1.FrkrClientSpec{DeliverTo: []frkrv1.SecretDelivery{
+				{Namespace: "team-a", SecretName: "orders-creds", Keys: map[string]string{"clientSecret": "password"}},
+				{Namespace: "other"},
+			}})
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			source := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, secretKey, source)).To(Succeed())
+			delivered := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, deliveredKey, delivered)).To(Succeed())
+			Expect(delivered.Data["password"]).To(Equal(source.Data["clientSecret"]))
+			Expect(delivered.Data["clientId"]).To(Equal([]byte("orders")))
+			Expect(delivered.Data).NotTo(HaveKey("clientSecret"))
+
+			denied := types.NamespacedName{Name: "frkr-client-orders-client", Namespace: "other"}
+			Expect(fakeClient.Get(ctx, denied, &corev1.Secret{})).NotTo(Succeed())
+
+			updated := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Deliveries).To(HaveLen(2))
+			Expect(updated.Status.Deliveries[0].Delivered).To(BeTrue())
+			Expect(updated.Status.Deliveries[1].Delivered).To(BeFalse())
+			Expect(meta.IsStatusConditionFalse(updated.Status.Conditions, "SecretsDelivered")).To(BeTrue())
+		})
+
+		It("should ignore the allow-list of a tenant that does not bind the client's namespace", func() {
+			Expect(fakeClient.Create(ctx, &frkrv1.FrkrTenant{
+				ObjectMeta: metav1.ObjectMeta{Name: "tenant-2", Namespace: "tenants"},
+				Spec:       frkrv1.FrkrTenantSpec{AllowedDeliveryNamespaces: []string{"*"}},
+			})).To(Succeed())
+			Expect(fakeClient.Create(ctx, &frkrv1.FrkrClient{
+				ObjectMeta: metav1.ObjectMeta{Name: "orders-client", Namespace: "default"},
+				Spec: frkrv1.FrkrClientSpec{TenantID: "tenant-2", ClientID: "orders", DeliverTo: []frkrv1.SecretDelivery{
+					{Namespace: "team-a", SecretName: "orders-creds"},
+				}},
+			})).To(Succeed())
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeClient.Get(ctx, deliveredKey, &corev1.Secret{})).NotTo(Succeed())
+
+			updated := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Deliveries[0].Message).To(ContainSubstring("no tenant tenant-2 binds namespace default"))
+
+			tenant := &frkrv1.FrkrTenant{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "tenant-2", Namespace: "tenants"}, tenant)).To(Succeed())
+			tenant.Spec.ClientNamespaces = []string{"def*"}
+			Expect(fakeClient.Update(ctx, tenant)).To(Succeed())
+
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeClient.Get(ctx, deliveredKey, &corev1.Secret{})).To(Succeed())
+		})
+
+		It("should remove delivered secrets when the delivery or the client is removed", func() {
+			createClient(frkrv1.FrkrClientSpec{DeliverTo: []frkrv1.SecretDelivery{
+				{Namespace: "team-a", SecretName: "orders-creds"},
+				{Namespace: "team-b"},
+			}})
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			crd := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, crd)).To(Succeed())
+			crd.Spec.DeliverTo = crd.Spec.DeliverTo[:1]
+			Expect(fakeClient.Update(ctx, crd)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			removed := types.NamespacedName{Name: "frkr-client-orders-client", Namespace: "team-b"}
+			Expect(fakeClient.Get(ctx, removed, &corev1.Secret{})).NotTo(Succeed())
+			Expect(fakeClient.Get(ctx, deliveredKey, &corev1.Secret{})).To(Succeed())
+
+			Expect(fakeClient.Get(ctx, req.NamespacedName, crd)).To(Succeed())
+			Expect(fakeClient.Delete(ctx, crd)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeClient.Get(ctx, deliveredKey, &corev1.Secret{})).NotTo(Succeed())
+		})
+
+		It("should not take over a secret it did not deliver", func() {
+			Expect(fakeClient.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: deliveredKey.Name, Namespace: deliveredKey.Namespace},
+				Data:       map[string][]byte{"password": []byte("unrelated")},
+			})).To(Succeed())
+			createClient(frkrv1.FrkrClientSpec{DeliverTo: []frkrv1.SecretDelivery{
+				{Namespace: "team-a", SecretName: "orders-creds"},
+			}})
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			existing := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, deliveredKey, existing)).To(Succeed())
+			Expect(existing.Data["password"]).To(Equal([]byte("unrelated")))
+
+			updated := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Deliveries[0].Message).To(ContainSubstring("not managed by this client"))
+		})
+
+		It("should map delivered secrets back to their client", func() {
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name:      "orders-creds",
+				Namespace: "team-a",
+				Labels:    map[string]string{deliveryClientNameLabel: "orders-client", deliveryClientNamespaceLabel: "default"},
+			}}
+			Expect(reconciler.clientsForSecret(ctx, secret)).To(ConsistOf(req))
+		})
+
+		It("should map a referenced secret to the clients reading it", func() {
+			createClient(frkrv1.FrkrClientSpec{SecretRef: &frkrv1.SecretKeyReference{Name: "orders-secret"}})
+
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "orders-secret", Namespace: "default"}}
+			Expect(reconciler.clientsForSecret(ctx, secret)).To(ConsistOf(req))
+			other := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "orders-secret", Namespace: "team-a"}}
+			Expect(reconciler.clientsForSecret(ctx, other)).To(BeEmpty())
+		})
+	})
+
+	Describe("mtls credentials", func() {
+		It("should issue a client certificate instead of a secret", func() {
+			createClient(frkrv1.FrkrClientSpec{CredentialType: frkrv1.ClientCredentialMTLS})
+
+			result, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", 60*24*time.Hour, time.Hour))
+
+			secret := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, secretKey, secret)).To(Succeed())
+			Expect(secret.Data).NotTo(HaveKey("clientSecret"))
+			Expect(secret.Data).To(HaveKey(corev1.TLSPrivateKeyKey))
+			Expect(secret.Data).To(HaveKey("ca.crt"))
+
+			cert, err := pki.ParseCertificate(secret.Data[corev1.TLSCertKey])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cert.Subject.CommonName).To(Equal("orders"))
+			Expect(cert.Subject.Organization).To(ConsistOf("tenant-1"))
+
+			ca := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, reconciler.CASecret, ca)).To(Succeed())
+			caCert, err := pki.ParseCertificate(ca.Data[corev1.TLSCertKey])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cert.CheckSignatureFrom(caCert)).To(Succeed())
+
+			updated := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Certificate).NotTo(BeNil())
+			Expect(updated.Status.Certificate.Fingerprint).To(Equal(pki.Fingerprint(cert)))
+			Expect(recorder.Events).To(Receive(ContainSubstring("CertificateIssued")))
+
+			// A second reconcile keeps the certificate
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			again := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, secretKey, again)).To(Succeed())
+			Expect(again.Data[corev1.TLSCertKey]).To(Equal(secret.Data[corev1.TLSCertKey]))
+		})
+
+		It("should renew the certificate on a rotation request", func() {
+			createClient(frkrv1.FrkrClientSpec{CredentialType: frkrv1.ClientCredentialMTLS})
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			before := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, before)).To(Succeed())
+
+			requestRotation("1")
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			after := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, after)).To(Succeed())
+			Expect(after.Status.Certificate.Fingerprint).NotTo(Equal(before.Status.Certificate.Fingerprint))
+			Expect(after.Status.ObservedRotationRequest).To(Equal("1"))
+			Expect(after.Status.LastRotation).NotTo(BeNil())
+			Eventually(recorder.Events).Should(Receive(ContainSubstring("CertificateRenewed")))
+		})
+
+		It("should not issue certificates past the credential expiry", func() {
+			expiresAt := metav1.NewTime(time.Now().Add(48 * time.Hour).Truncate(time.Second))
+			createClient(frkrv1.FrkrClientSpec{CredentialType: frkrv1.ClientCredentialMTLS, ExpiresAt: &expiresAt})
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			updated := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Certificate.NotAfter.Time).To(BeTemporally("<=", expiresAt.Time))
+			Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, "CertificateIssued")).To(BeTrue())
+		})
+
+		It("should register the staged certificate again after a failed reconcile", func() {
+			var registered []string
+			failRegistration := true
+			db, _ := newFakeDB(func(query string, args []driver.NamedValue) (*fakeRows, error) {
+				switch {
+				case strings.Contains(query, "INSERT INTO client_certificates"):
+					registered = append(registered, args[0].Value.(string))
+					if failRegistration {
+						return nil, errors.New("connection reset")
+					}
+				case strings.Contains(query, "INSERT INTO clients"):
+					return clientRow("client-uuid", "tenant-1", "orders", ""), nil
+				}
+				return nil, nil
+			})
+			reconciler.DB = db
+			createClient(frkrv1.FrkrClientSpec{CredentialType: frkrv1.ClientCredentialMTLS})
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).To(HaveOccurred())
+			staged := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, secretKey, staged)).To(Succeed())
+			Expect(staged.Data).To(HaveKey(pendingTLSCertKey))
+
+			failRegistration = false
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(registered).To(HaveLen(2))
+			Expect(registered[1]).To(Equal(registered[0]))
+
+			secret := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, secretKey, secret)).To(Succeed())
+			Expect(secret.Data[corev1.TLSCertKey]).To(Equal(staged.Data[pendingTLSCertKey]))
+			Expect(secret.Data).NotTo(HaveKey(pendingTLSCertKey))
+			Expect(secret.Data).NotTo(HaveKey(pendingTLSKeyKey))
+		})
+
+		It("should not issue a certificate for an expired client", func() {
+			expiresAt := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+			createClient(frkrv1.FrkrClientSpec{CredentialType: frkrv1.ClientCredentialMTLS, ExpiresAt: &expiresAt})
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			secret := &corev1.Secret{}
+			Expect(fakeClient.Get(ctx, secretKey, secret)).To(Succeed())
+			Expect(secret.Data).NotTo(HaveKey(corev1.TLSCertKey))
+			Expect(secret.Data).NotTo(HaveKey("clientSecret"))
+
+			updated := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Certificate).To(BeNil())
+			cond := meta.FindStatusCondition(updated.Status.Conditions, "CertificateIssued")
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal("CredentialExpired"))
+		})
+	})
+
+	Describe("network restrictions and rate limits", func() {
+		It("should echo the normalized policy in status", func() {
+			createClient(frkrv1.FrkrClientSpec{
+				AllowedCIDRs: []string{"203.0.113.7", "198.51.100.12/24", "198.51.100.0/24", "2001:db8::1/64"},
+				RateLimit:    &frkrv1.ClientRateLimit{RequestsPerSecond: 50},
+			})
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			updated := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.AllowedCIDRs).To(Equal([]string{"203.0.113.7/32", "198.51.100.0/24", "2001:db8::/64"}))
+			Expect(updated.Status.RateLimit).To(Equal(&frkrv1.ClientRateLimit{RequestsPerSecond: 50, Burst: 50}))
+			Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, "PolicyValid")).To(BeTrue())
+		})
+
+		It("should reject an invalid policy", func() {
+			createClient(frkrv1.FrkrClientSpec{AllowedCIDRs: []string{"10.0.0.0/33", "partner-egress"}})
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			updated := &frkrv1.FrkrClient{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Phase).To(Equal("Rejected"))
+			cond := meta.FindStatusCondition(updated.Status.Conditions, "PolicyValid")
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Message).To(ContainSubstring(`"partner-egress"`))
+			Expect(fakeClient.Get(ctx, secretKey, &corev1.Secret{})).NotTo(Succeed())
+			Expect(recorder.Events).To(Receive(ContainSubstring("InvalidPolicy")))
+		})
+	})
+})
@@ -0,0 +1,249 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
+)
+
+// Labels identifying the FrkrClient a delivered Secret belongs to. Owner references
+// cannot cross namespaces, so delivered Secrets are tracked by label instead.
+const (
+	deliveryClientNameLabel      = "frkr.io/client-name"
+	deliveryClientNamespaceLabel = "frkr.io/client-namespace"
+)
+
+// deliveryLabels returns the labels marking a Secret as delivered for crd
+func deliveryLabels(crd *frkrv1.FrkrClient) map[string]string {
+	return map[string]string{
+		deliveryClientNameLabel:      crd.Name,
+		deliveryClientNamespaceLabel: crd.Namespace,
+	}
+}
+
+// isDeliveredFor reports whether a Secret was delivered for crd
+func isDeliveredFor(secret *corev1.Secret, crd *frkrv1.FrkrClient) bool {
+	return secret.Labels[deliveryClientNameLabel] == crd.Name &&
+		secret.Labels[deliveryClientNamespaceLabel] == crd.Namespace
+}
+
+// tenantForClient returns the FrkrTenant of a client, matched by database ID or name.
+// spec.tenantId is chosen freely by whoever writes the client, so only tenants that bind
+// the client's namespace are considered; nil means no such tenant.
+func (r *ClientReconciler) tenantForClient(ctx context.Context, crd *frkrv1.FrkrClient) (*frkrv1.FrkrTenant, error) {
+	var tenantList frkrv1.FrkrTenantList
+	if err := r.List(ctx, &tenantList); err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	for i := range tenantList.Items {
+		t := &tenantList.Items[i]
+		if (t.Status.ID != "" && t.Status.ID == crd.Spec.TenantID) || t.Name == crd.Spec.TenantID || (t.Spec.Name != "" && t.Spec.Name == crd.Spec.TenantID) {
+			if tenantBindsNamespace(t, crd.Namespace) {
+				return t, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// tenantBindsNamespace reports whether clients in namespace are bound to the tenant: the
+// tenant's own namespace, or one matching its spec.clientNamespaces
+func tenantBindsNamespace(tenant *frkrv1.FrkrTenant, namespace string) bool {
+	if tenant.Namespace == namespace {
+		return true
+	}
+	for _, pattern := range tenant.Spec.ClientNamespaces {
+		if ok, _ := path.Match(pattern, namespace); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// deliveryAllowed reports whether the tenant may deliver credentials into namespace.
+// The client's own namespace is always allowed.
+func deliveryAllowed(tenant *frkrv1.FrkrTenant, crd *frkrv1.FrkrClient, namespace string) bool {
+	if namespace == crd.Namespace {
+		return true
+	}
+	if tenant == nil {
+		return false
+	}
+	for _, pattern := range tenant.Spec.AllowedDeliveryNamespaces {
+		if ok, _ := path.Match(pattern, namespace); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// syncDeliveries replicates the credential data into the Secrets listed in spec.deliverTo
+// and removes delivered Secrets that are no longer listed or allowed
+func (r *ClientReconciler) syncDeliveries(ctx context.Context, crd *frkrv1.FrkrClient, data map[string][]byte) ([]frkrv1.SecretDeliveryStatus, error) {
+	if len(crd.Spec.DeliverTo) == 0 {
+		return nil, r.removeDeliveries(ctx, crd, nil)
+	}
+
+	tenant, err := r.tenantForClient(ctx, crd)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []frkrv1.SecretDeliveryStatus
+	keep := map[types.NamespacedName]bool{}
+	for _, delivery := range crd.Spec.DeliverTo {
+		name := delivery.SecretName
+		if name == "" {
+			name = fmt.Sprintf("frkr-client-%s", crd.Name)
+		}
+		status := frkrv1.SecretDeliveryStatus{Namespace: delivery.Namespace, SecretName: name}
+		key := types.NamespacedName{Namespace: delivery.Namespace, Name: name}
+
+		switch {
+		case !deliveryAllowed(tenant, crd, delivery.Namespace):
+			status.Message = fmt.Sprintf("namespace %s is not in the tenant's allowedDeliveryNamespaces", delivery.Namespace)
+			if tenant == nil {
+				status.Message = fmt.Sprintf("namespace %s is not allowed: no tenant %s binds namespace %s", delivery.Namespace, crd.Spec.TenantID, crd.Namespace)
+			}
+		case key == (types.NamespacedName{Namespace: crd.Namespace, Name: fmt.Sprintf("frkr-client-%s", crd.Name)}):
+			status.Message = "the credentials Secret itself cannot be a delivery target"
+		default:
+			message, err := r.deliverSecret(ctx, crd, key, delivery.Keys, data)
+			if err != nil {
+				return nil, err
+			}
+			status.Delivered = message == ""
+			status.Message = message
+			if status.Delivered {
+				keep[key] = true
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	if err := r.removeDeliveries(ctx, crd, keep); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// deliverSecret creates or updates a single delivered Secret. It refuses to take over a
+// Secret that was not delivered for this client and returns the reason instead.
+func (r *ClientReconciler) deliverSecret(ctx context.Context, crd *frkrv1.FrkrClient, key types.NamespacedName, keys map[string]string, data map[string][]byte) (string, error) {
+	delivered := make(map[string][]byte, len(data))
+	for k, v := range data {
+		if renamed := keys[k]; renamed != "" {
+			k = renamed
+		}
+		delivered[k] = v
+	}
+
+	var existing corev1.Secret
+	err := r.Get(ctx, key, &existing)
+	if client.IgnoreNotFound(err) != nil {
+		return "", fmt.Errorf("failed to get secret %s: %w", key, err)
+	}
+	if err != nil {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      key.Name,
+				Namespace: key.Namespace,
+				Labels:    deliveryLabels(crd),
+			},
+			Data: delivered,
+		}
+		if err := r.Create(ctx, secret); err != nil {
+			return "", fmt.Errorf("failed to create secret %s: %w", key, err)
+		}
+		return "", nil
+	}
+
+	if !isDeliveredFor(&existing, crd) {
+		return fmt.Sprintf("Secret %s already exists and is not managed by this client", key), nil
+	}
+	existing.Data = delivered
+	if err := r.Update(ctx, &existing); err != nil {
+		return "", fmt.Errorf("failed to update secret %s: %w", key, err)
+	}
+	return "", nil
+}
+
+// removeDeliveries deletes the Secrets delivered for crd, except those in keep
+func (r *ClientReconciler) removeDeliveries(ctx context.Context, crd *frkrv1.FrkrClient, keep map[types.NamespacedName]bool) error {
+	var secretList corev1.SecretList
+	if err := r.List(ctx, &secretList, client.MatchingLabels(deliveryLabels(crd))); err != nil {
+		return fmt.Errorf("failed to list delivered secrets: %w", err)
+	}
+	for i := range secretList.Items {
+		secret := &secretList.Items[i]
+		if keep[client.ObjectKeyFromObject(secret)] {
+			continue
+		}
+		if err := r.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete delivered secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+		log.FromContext(ctx).Info("removed delivered secret", "clientId", crd.Spec.ClientID, "namespace", secret.Namespace, "secret", secret.Name)
+	}
+	return nil
+}
+
+// setDeliveredCondition reports whether every delivery in spec.deliverTo is in sync
+func setDeliveredCondition(crd *frkrv1.FrkrClient) {
+	if len(crd.Spec.DeliverTo) == 0 {
+		meta.RemoveStatusCondition(&crd.Status.Conditions, "SecretsDelivered")
+		return
+	}
+	for _, d := range crd.Status.Deliveries {
+		if !d.Delivered {
+			meta.SetStatusCondition(&crd.Status.Conditions, metav1.Condition{
+				Type:    "SecretsDelivered",
+				Status:  metav1.ConditionFalse,
+				Reason:  "DeliveryFailed",
+				Message: fmt.Sprintf("%s/%s: %s", d.Namespace, d.SecretName, d.Message),
+			})
+			return
+		}
+	}
+	meta.SetStatusCondition(&crd.Status.Conditions, metav1.Condition{
+		Type:    "SecretsDelivered",
+		Status:  metav1.ConditionTrue,
+		Reason:  "Delivered",
+		Message: fmt.Sprintf("Credentials delivered to %d namespace(s)", len(crd.Status.Deliveries)),
+	})
+}
+
+// clientsForTenant maps a FrkrTenant to the clients that deliver credentials on its behalf,
+// so changes to the allow-list are applied
+func (r *ClientReconciler) clientsForTenant(ctx context.Context, obj client.Object) []reconcile.Request {
+	tenant, ok := obj.(*frkrv1.FrkrTenant)
+	if !ok {
+		return nil
+	}
+
+	var clientList frkrv1.FrkrClientList
+	if err := r.List(ctx, &clientList); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list clients for tenant", "tenant", obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, c := range clientList.Items {
+		if len(c.Spec.DeliverTo) == 0 {
+			continue
+		}
+		if (tenant.Status.ID != "" && c.Spec.TenantID == tenant.Status.ID) || c.Spec.TenantID == tenant.Name || (tenant.Spec.Name != "" && c.Spec.TenantID == tenant.Spec.Name) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&c)})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
+	"github.com/frkr-io/frkr-operator/internal/infra"
+)
+
+// clientPolicy is the effective network restriction and rate limit of a client
+type clientPolicy struct {
+	AllowedCIDRs []string
+	RateLimit    *frkrv1.ClientRateLimit
+}
+
+// resolveClientPolicy validates spec.allowedCIDRs and spec.rateLimit. Single addresses
+// become /32 (or /128) blocks, host bits are cleared and duplicates removed. The returned
+// problems describe invalid entries; the policy is only valid if there are none.
+func resolveClientPolicy(crd *frkrv1.FrkrClient) (clientPolicy, []string) {
+	var policy clientPolicy
+	var problems []string
+
+	for _, value := range crd.Spec.AllowedCIDRs {
+		value = strings.TrimSpace(value)
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(value)
+			if addrErr != nil {
+				problems = append(problems, fmt.Sprintf("invalid CIDR %q", value))
+				continue
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		cidr := prefix.Masked().String()
+		if !slices.Contains(policy.AllowedCIDRs, cidr) {
+			policy.AllowedCIDRs = append(policy.AllowedCIDRs, cidr)
+		}
+	}
+
+	if limit := crd.Spec.RateLimit; limit != nil {
+		effective := *limit
+		if effective.Burst == 0 {
+			effective.Burst = effective.RequestsPerSecond
+		}
+		switch {
+		case effective.RequestsPerSecond < 1:
+			problems = append(problems, "rateLimit.requestsPerSecond must be at least 1")
+		case effective.Burst < 1:
+			problems = append(problems, "rateLimit.burst must be at least 1")
+		default:
+			policy.RateLimit = &effective
+		}
+	}
+	return policy, problems
+}
+
+// dbRateLimit converts the rate limit for the database
+func (p clientPolicy) dbRateLimit() *infra.ClientRateLimit {
+	if p.RateLimit == nil {
+		return nil
+	}
+	return &infra.ClientRateLimit{
+		RequestsPerSecond: int(p.RateLimit.RequestsPerSecond),
+		Burst:             int(p.RateLimit.Burst),
+	}
+}
+
+// matchesStatus reports whether the policy is the one echoed in the client's status
+func (p clientPolicy) matchesStatus(status *frkrv1.FrkrClientStatus) bool {
+	if !slices.Equal(p.AllowedCIDRs, status.AllowedCIDRs) {
+		return false
+	}
+	if p.RateLimit == nil || status.RateLimit == nil {
+		return p.RateLimit == nil && status.RateLimit == nil
+	}
+	return *p.RateLimit == *status.RateLimit
+}
+
+// setPolicyValidCondition reports whether spec.allowedCIDRs and spec.rateLimit are valid
+func setPolicyValidCondition(crd *frkrv1.FrkrClient, problems []string) {
+	if len(crd.Spec.AllowedCIDRs) == 0 && crd.Spec.RateLimit == nil {
+		meta.RemoveStatusCondition(&crd.Status.Conditions, "PolicyValid")
+		return
+	}
+	if len(problems) > 0 {
+		meta.SetStatusCondition(&crd.Status.Conditions, metav1.Condition{
+			Type:    "PolicyValid",
+			Status:  metav1.ConditionFalse,
+			Reason:  "InvalidPolicy",
+			Message: strings.Join(problems, "; "),
+		})
+		return
+	}
+
+	var parts []string
+	if len(crd.Spec.AllowedCIDRs) > 0 {
+		parts = append(parts, fmt.Sprintf("%d allowed CIDR(s)", len(crd.Spec.AllowedCIDRs)))
+	}
+	if limit := crd.Spec.RateLimit; limit != nil {
+		parts = append(parts, fmt.Sprintf("rate limit %d req/s", limit.RequestsPerSecond))
+	}
+	meta.SetStatusCondition(&crd.Status.Conditions, metav1.Condition{
+		Type:    "PolicyValid",
+		Status:  metav1.ConditionTrue,
+		Reason:  "PolicyApplied",
+		Message: fmt.Sprintf("Enforcing %s", strings.Join(parts, " and ")),
+	})
+}
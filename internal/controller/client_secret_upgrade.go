@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/frkr-io/frkr-operator/internal/infra"
+)
+
+// ClientSecretHashStatus reports the progress of hashing plaintext client secrets
+type ClientSecretHashStatus struct {
+	// State is Pending, Running, Complete, Failed or Disabled (not enabled, or no database
+	// configured)
+	State       string     `json:"state"`
+	Total       int        `json:"total"`
+	Hashed      int        `json:"hashed"`
+	Failed      int        `json:"failed"`
+	Error       string     `json:"error,omitempty"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+// ClientSecretHashUpgrade hashes client secrets stored in plaintext by earlier versions of
+// the operator. It runs once on the leader and serves its progress as JSON.
+//
+// Gateways verify client secrets against the clients table, so the upgrade must only be
+// enabled once every gateway compares hashes; frkr-common v0.3.3 and earlier compare the
+// stored secret as plaintext and reject hashed ones.
+type ClientSecretHashUpgrade struct {
+	DB *infra.DB
+	// Enabled opts in to rewriting the stored secrets with DB.SecretHash
+	Enabled bool
+
+	mu     sync.Mutex
+	status ClientSecretHashStatus
+}
+
+// Start runs the upgrade. Failures are reported on the status endpoint rather than
+// stopping the manager; the upgrade is retried on the next operator start.
+func (u *ClientSecretHashUpgrade) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("client-secret-hash-upgrade")
+
+	if !u.Enabled || u.DB == nil || u.DB.SecretHash == infra.SecretHashPlaintext {
+		u.update(func(s *ClientSecretHashStatus) { s.State = "Disabled" })
+		return nil
+	}
+
+	started := time.Now()
+	u.update(func(s *ClientSecretHashStatus) {
+		s.State = "Running"
+		s.StartedAt = &started
+	})
+
+	err := u.DB.HashClientSecrets(ctx, func(hashed, failed, total int) {
+		u.update(func(s *ClientSecretHashStatus) {
+			s.Hashed, s.Failed, s.Total = hashed, failed, total
+		})
+		if total > 0 {
+			logger.Info("hashing client secrets", "hashed", hashed, "failed", failed, "total", total)
+		}
+	}, func(clientID string, err error) {
+		logger.Error(err, "failed to hash client secret", "clientId", clientID)
+	})
+
+	completed := time.Now()
+	u.update(func(s *ClientSecretHashStatus) {
+		s.CompletedAt = &completed
+		switch {
+		case err != nil:
+			s.State = "Failed"
+			s.Error = err.Error()
+		case s.Failed > 0:
+			s.State = "Failed"
+			s.Error = "some client secrets could not be hashed; see the operator logs"
+		default:
+			s.State = "Complete"
+		}
+	})
+	if err != nil {
+		logger.Error(err, "failed to hash client secrets")
+	}
+	return nil
+}
+
+// NeedLeaderElection makes sure only one operator replica rewrites the clients table
+func (u *ClientSecretHashUpgrade) NeedLeaderElection() bool {
+	return true
+}
+
+// Status returns a copy of the current progress
+func (u *ClientSecretHashUpgrade) Status() ClientSecretHashStatus {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.status.State == "" {
+		return ClientSecretHashStatus{State: "Pending"}
+	}
+	return u.status
+}
+
+// ServeHTTP serves the upgrade progress on the operator's status endpoint
+func (u *ClientSecretHashUpgrade) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]ClientSecretHashStatus{
+		"clientSecretHashing": u.Status(),
+	})
+}
+
+func (u *ClientSecretHashUpgrade) update(fn func(*ClientSecretHashStatus)) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	fn(&u.status)
+}
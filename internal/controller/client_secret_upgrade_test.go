@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ClientSecretHashUpgrade", func() {
+	It("should report Pending before it runs", func() {
+		upgrade := &ClientSecretHashUpgrade{}
+		Expect(upgrade.Status().State).To(Equal("Pending"))
+	})
+
+	It("should report Disabled without a database on the status endpoint", func() {
+		upgrade := &ClientSecretHashUpgrade{}
+		Expect(upgrade.Start(context.Background())).To(Succeed())
+
+		rec := httptest.NewRecorder()
+		upgrade.ServeHTTP(rec, httptest.NewRequest("GET", "/status", nil))
+		Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		var body map[string]ClientSecretHashStatus
+		Expect(json.Unmarshal(rec.Body.Bytes(), &body)).To(Succeed())
+		Expect(body["clientSecretHashing"].State).To(Equal("Disabled"))
+	})
+
+	It("should leave the database alone unless enabled", func() {
+		db, fake := newFakeDB(nil)
+		upgrade := &ClientSecretHashUpgrade{DB: db}
+		Expect(upgrade.Start(context.Background())).To(Succeed())
+
+		Expect(upgrade.Status().State).To(Equal("Disabled"))
+		Expect(fake.Executed("clients")).To(BeEmpty())
+	})
+})
@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"os"
+
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
@@ -14,8 +16,7 @@ func SetupControllers(mgr manager.Manager) error {
 	// Get infrastructure config
 	config, err := infra.GetConfigFromEnv()
 	if err != nil {
-		setupLog.Error(err, "unable to get infrastructure config")
-		// Continue anyway, controllers will handle nil connections
+		return err
 	}
 
 	var db *infra.DB
@@ -31,19 +32,32 @@ func SetupControllers(mgr manager.Manager) error {
 		kafkaAdmin = infra.NewKafkaAdmin(config.BrokerURL)
 	}
 
+	// Credential Secret naming can be customized, e.g. to match an existing convention
+	secretNameTemplate := DefaultUserSecretNameTemplate
+	if t := os.Getenv("USER_SECRET_NAME_TEMPLATE"); t != "" {
+		secretNameTemplate = t
+	}
+	userSecretNames, err := ParseUserSecretNameTemplate(secretNameTemplate)
+	if err != nil {
+		return err
+	}
+
 	// Setup User controller
 	if err := (&UserReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		DB:     db,
+		Client:             mgr.GetClient(),
+		Scheme:             mgr.GetScheme(),
+		DB:                 db,
+		Recorder:           mgr.GetEventRecorderFor("frkruser-controller"),
+		SecretNameTemplate: userSecretNames,
 	}).SetupWithManager(mgr); err != nil {
 		return err
 	}
 
 	// Setup AuthConfig controller
 	if err := (&AuthConfigReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("frkrauthconfig-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		return err
 	}
@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frkr-io/frkr-operator/internal/infra"
+)
+
+// fakeRows is the answer of a fakeSQL handler; Affected is returned for Exec
+type fakeRows struct {
+	Columns  []string
+	Rows     [][]driver.Value
+	Affected int64
+}
+
+// fakeSQL is a database/sql driver for controller tests. It records every statement and
+// answers it from handler; statements the handler does not know (nil, nil) return no rows
+// and affect one row.
+type fakeSQL struct {
+	mu         sync.Mutex
+	statements []string
+	handler    func(query string, args []driver.NamedValue) (*fakeRows, error)
+}
+
+// newFakeDB returns an infra.DB backed by a fakeSQL driver
+func newFakeDB(handler func(query string, args []driver.NamedValue) (*fakeRows, error)) (*infra.DB, *fakeSQL) {
+	f := &fakeSQL{handler: handler}
+	return &infra.DB{DB: sql.OpenDB(f)}, f
+}
+
+// Executed returns the recorded statements containing fragment
+func (f *fakeSQL) Executed(fragment string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var matched []string
+	for _, s := range f.statements {
+		if strings.Contains(s, fragment) {
+			matched = append(matched, s)
+		}
+	}
+	return matched
+}
+
+func (f *fakeSQL) answer(query string, args []driver.NamedValue) (*fakeRows, error) {
+	f.mu.Lock()
+	f.statements = append(f.statements, query)
+	f.mu.Unlock()
+	if f.handler == nil {
+		return nil, nil
+	}
+	return f.handler(query, args)
+}
+
+// tenantRow answers a tenant lookup with tenantID
+func tenantRow(tenantID string) *fakeRows {
+	now := time.Now()
+	return &fakeRows{
+		Columns: []string{"id", "name", "plan", "created_at", "updated_at", "deleted_at"},
+		Rows:    [][]driver.Value{{tenantID, tenantID, "free", now, now, nil}},
+	}
+}
+
+// clientRow answers a client insert or lookup
+func clientRow(id, tenantID, clientID, secretHash string) *fakeRows {
+	now := time.Now()
+	return &fakeRows{
+		Columns: []string{"id", "tenant_id", "stream_id", "client_id", "client_secret", "created_at", "updated_at", "deleted_at"},
+		Rows:    [][]driver.Value{{id, tenantID, nil, clientID, secretHash, now, now, nil}},
+	}
+}
+
+func (f *fakeSQL) Connect(context.Context) (driver.Conn, error) { return fakeConn{f}, nil }
+func (f *fakeSQL) Driver() driver.Driver                        { return fakeDriver{f} }
+
+type fakeDriver struct{ f *fakeSQL }
+
+func (d fakeDriver) Open(string) (driver.Conn, error) { return fakeConn(d), nil }
+
+type fakeConn struct{ f *fakeSQL }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.f, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+func (c fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	rows, err := c.f.answer(query, args)
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		return driver.RowsAffected(1), nil
+	}
+	return driver.RowsAffected(rows.Affected), nil
+}
+
+func (c fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := c.f.answer(query, args)
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = &fakeRows{}
+	}
+	return &fakeCursor{rows: rows}, nil
+}
+
+type fakeStmt struct {
+	f     *fakeSQL
+	query string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return fakeConn{s.f}.ExecContext(context.Background(), s.query, named(args))
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return fakeConn{s.f}.QueryContext(context.Background(), s.query, named(args))
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	values := make([]driver.NamedValue, len(args))
+	for i, a := range args {
+		values[i] = driver.NamedValue{Ordinal: i + 1, Value: a}
+	}
+	return values
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeCursor struct {
+	rows *fakeRows
+	next int
+}
+
+func (c *fakeCursor) Columns() []string {
+	if len(c.rows.Columns) == 0 && len(c.rows.Rows) > 0 {
+		return make([]string, len(c.rows.Rows[0]))
+	}
+	return c.rows.Columns
+}
+
+func (c *fakeCursor) Close() error { return nil }
+
+func (c *fakeCursor) Next(dest []driver.Value) error {
+	if c.next >= len(c.rows.Rows) {
+		return io.EOF
+	}
+	copy(dest, c.rows.Rows[c.next])
+	c.next++
+	return nil
+}
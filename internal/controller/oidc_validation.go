@@ -0,0 +1,219 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
+)
+
+// defaultOIDCValidationInterval is how often OIDC providers are re-validated unless
+// spec.oidcConfig.validationInterval is set
+const defaultOIDCValidationInterval = time.Hour
+
+// oidcRetryInterval is how soon a failed OIDC validation is retried
+const oidcRetryInterval = time.Minute
+
+// oidcRequestTimeout bounds each request to an OIDC provider
+const oidcRequestTimeout = 10 * time.Second
+
+// maxOIDCResponseSize bounds the provider documents read by the operator
+const maxOIDCResponseSize = 1 << 20
+
+// oidcConditionTypes are the conditions set by validateOIDC
+var oidcConditionTypes = []string{"OIDCConfigured", "ProviderDiscovered", "JWKSAvailable", "SecretRefResolved", "ScopesSupported"}
+
+// oidcProviderMetadata is the part of the OpenID provider metadata the operator checks
+type oidcProviderMetadata struct {
+	Issuer          string   `json:"issuer"`
+	JWKSURI         string   `json:"jwks_uri"`
+	ScopesSupported []string `json:"scopes_supported"`
+}
+
+// jsonWebKeySet is a JWKS document
+type jsonWebKeySet struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Use string `json:"use"`
+	} `json:"keys"`
+}
+
+// validateOIDC checks the OIDC provider of an auth config: the discovery document, the
+// JWKS, the client secret and the requested scopes. Each check is reported as a condition;
+// it returns whether all of them passed. Provider problems are not returned as errors.
+func (r *AuthConfigReconciler) validateOIDC(ctx context.Context, authConfig *frkrv1.FrkrAuthConfig) (bool, error) {
+	conditions := &authConfig.Status.Conditions
+	cfg := authConfig.Spec.OIDCConfig
+	if cfg == nil || cfg.IssuerURL == "" || cfg.ClientID == "" {
+		setCondition(conditions, "OIDCConfigured", false, "MissingOIDCConfig", "spec.oidcConfig with issuerUrl and clientId is required")
+		for _, t := range oidcConditionTypes[1:] {
+			meta.RemoveStatusCondition(conditions, t)
+		}
+		return false, nil
+	}
+	setCondition(conditions, "OIDCConfigured", true, "Configured", fmt.Sprintf("Issuer %s, client %s", cfg.IssuerURL, cfg.ClientID))
+	valid := true
+
+	// Client secret
+	ref := &frkrv1.SecretKeyReference{Name: cfg.ClientSecretRef, Key: cfg.ClientSecretKey}
+	_, problem, err := readSecretRef(ctx, r.Client, authConfig.Namespace, ref, "clientSecret")
+	if err != nil {
+		return false, err
+	}
+	if cfg.ClientSecretRef == "" {
+		problem = "spec.oidcConfig.clientSecretRef is not set"
+	}
+	setSecretRefCondition(conditions, ref, problem)
+	valid = valid && problem == ""
+
+	// Discovery document
+	var metadata oidcProviderMetadata
+	discoveryURL := strings.TrimSuffix(cfg.IssuerURL, "/") + "/.well-known/openid-configuration"
+	if err := r.fetchOIDCDocument(ctx, discoveryURL, &metadata); err != nil {
+		setCondition(conditions, "ProviderDiscovered", false, "DiscoveryFailed", err.Error())
+		setUnknownCondition(conditions, "JWKSAvailable", "DiscoveryFailed", "Provider metadata could not be read")
+		setUnknownCondition(conditions, "ScopesSupported", "DiscoveryFailed", "Provider metadata could not be read")
+		return false, nil
+	}
+	switch {
+	case strings.TrimSuffix(metadata.Issuer, "/") != strings.TrimSuffix(cfg.IssuerURL, "/"):
+		setCondition(conditions, "ProviderDiscovered", false, "IssuerMismatch", fmt.Sprintf("Provider reports issuer %q, expected %q", metadata.Issuer, cfg.IssuerURL))
+		valid = false
+	case metadata.JWKSURI == "":
+		setCondition(conditions, "ProviderDiscovered", false, "MissingJWKSURI", "Provider metadata has no jwks_uri")
+		valid = false
+	default:
+		setCondition(conditions, "ProviderDiscovered", true, "Discovered", fmt.Sprintf("Provider metadata read from %s", discoveryURL))
+	}
+
+	// Signing keys
+	if metadata.JWKSURI == "" {
+		setUnknownCondition(conditions, "JWKSAvailable", "MissingJWKSURI", "Provider metadata has no jwks_uri")
+	} else {
+		var jwks jsonWebKeySet
+		if err := r.fetchOIDCDocument(ctx, metadata.JWKSURI, &jwks); err != nil {
+			setCondition(conditions, "JWKSAvailable", false, "JWKSUnavailable", err.Error())
+			valid = false
+		} else if n := signingKeyCount(&jwks); n == 0 {
+			setCondition(conditions, "JWKSAvailable", false, "NoSigningKeys", fmt.Sprintf("JWKS at %s has no signing keys", metadata.JWKSURI))
+			valid = false
+		} else {
+			setCondition(conditions, "JWKSAvailable", true, "KeysLoaded", fmt.Sprintf("%d signing key(s) at %s", n, metadata.JWKSURI))
+		}
+	}
+
+	// Scopes
+	var unsupported []string
+	if len(metadata.ScopesSupported) > 0 {
+		for _, scope := range cfg.Scopes {
+			if !slices.Contains(metadata.ScopesSupported, scope) {
+				unsupported = append(unsupported, scope)
+			}
+		}
+	}
+	switch {
+	case len(unsupported) > 0:
+		setCondition(conditions, "ScopesSupported", false, "UnsupportedScopes", fmt.Sprintf("Provider does not support scope(s): %s", strings.Join(unsupported, ", ")))
+		valid = false
+	case len(metadata.ScopesSupported) == 0:
+		setCondition(conditions, "ScopesSupported", true, "NotAdvertised", "Provider does not advertise scopes_supported; scopes not checked")
+	default:
+		setCondition(conditions, "ScopesSupported", true, "Supported", fmt.Sprintf("%d requested scope(s) supported", len(cfg.Scopes)))
+	}
+
+	return valid, nil
+}
+
+// clearOIDCConditions removes the OIDC conditions when no OIDC provider is configured
+func clearOIDCConditions(authConfig *frkrv1.FrkrAuthConfig) {
+	for _, t := range oidcConditionTypes {
+		meta.RemoveStatusCondition(&authConfig.Status.Conditions, t)
+	}
+	authConfig.Status.LastOIDCValidation = nil
+}
+
+// oidcValidationInterval returns how often the provider of an auth config is re-validated
+func oidcValidationInterval(cfg *frkrv1.OIDCConfig) time.Duration {
+	if cfg != nil && cfg.ValidationInterval != nil && cfg.ValidationInterval.Duration > 0 {
+		return cfg.ValidationInterval.Duration
+	}
+	return defaultOIDCValidationInterval
+}
+
+// fetchOIDCDocument reads a JSON document from an OIDC provider
+func (r *AuthConfigReconciler) fetchOIDCDocument(ctx context.Context, rawURL string, v any) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("invalid URL %q", rawURL)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, oidcRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request for %s: %w", rawURL, err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := r.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch %s: %s", rawURL, resp.Status)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxOIDCResponseSize)).Decode(v); err != nil {
+		return fmt.Errorf("invalid JSON at %s: %w", rawURL, err)
+	}
+	return nil
+}
+
+// signingKeyCount counts the keys in a JWKS that can verify token signatures
+func signingKeyCount(jwks *jsonWebKeySet) int {
+	n := 0
+	for _, key := range jwks.Keys {
+		if key.Kty != "" && (key.Use == "" || key.Use == "sig") {
+			n++
+		}
+	}
+	return n
+}
+
+// setCondition sets a True or False condition
+func setCondition(conditions *[]metav1.Condition, conditionType string, ok bool, reason, message string) {
+	status := metav1.ConditionFalse
+	if ok {
+		status = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:    conditionType,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+// setUnknownCondition sets a condition whose check could not run
+func setUnknownCondition(conditions *[]metav1.Condition, conditionType, reason, message string) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:    conditionType,
+		Status:  metav1.ConditionUnknown,
+		Reason:  reason,
+		Message: message,
+	})
+}
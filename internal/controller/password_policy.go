@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/frkr-io/frkr-common/util"
+	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
+)
+
+// maxPasswordGenerationAttempts bounds how often a generated password is retried against the policy
+const maxPasswordGenerationAttempts = 20
+
+// findPasswordPolicy returns the password policy in effect in the namespace, if any
+func findPasswordPolicy(ctx context.Context, c client.Client, namespace string) (*frkrv1.PasswordPolicy, error) {
+	source, err := passwordPolicySource(ctx, c, namespace)
+	if err != nil || source == nil {
+		return nil, err
+	}
+	return source.Spec.PasswordPolicy, nil
+}
+
+// passwordPolicySource returns the FrkrAuthConfig whose password policy applies to the
+// namespace. If several auth configs define one, the oldest wins (ties broken by name), so
+// the policy does not depend on list order; the others report it in their status.
+func passwordPolicySource(ctx context.Context, c client.Client, namespace string) (*frkrv1.FrkrAuthConfig, error) {
+	var configs frkrv1.FrkrAuthConfigList
+	if err := c.List(ctx, &configs, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list auth configs: %w", err)
+	}
+	var source *frkrv1.FrkrAuthConfig
+	for i := range configs.Items {
+		cfg := &configs.Items[i]
+		if cfg.Spec.PasswordPolicy == nil {
+			continue
+		}
+		if source == nil || createdBefore(cfg, source) {
+			source = cfg
+		}
+	}
+	return source, nil
+}
+
+// createdBefore reports whether a was created before b, breaking ties by name
+func createdBefore(a, b client.Object) bool {
+	ta, tb := a.GetCreationTimestamp(), b.GetCreationTimestamp()
+	if !ta.Equal(&tb) {
+		return ta.Before(&tb)
+	}
+	return a.GetName() < b.GetName()
+}
+
+// validatePassword checks a password against the policy and describes every violation
+func validatePassword(policy *frkrv1.PasswordPolicy, password string) error {
+	if policy == nil {
+		return nil
+	}
+
+	var violations []string
+	if policy.MinLength > 0 && len(password) < policy.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters", policy.MinLength))
+	}
+
+	for _, class := range policy.RequiredClasses {
+		if !containsClass(password, class) {
+			violations = append(violations, fmt.Sprintf("must contain a %s character", class))
+		}
+	}
+
+	lower := strings.ToLower(password)
+	for _, word := range policy.DisallowedWords {
+		if word != "" && strings.Contains(lower, strings.ToLower(word)) {
+			violations = append(violations, fmt.Sprintf("must not contain %q", word))
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("password %s", strings.Join(violations, ", "))
+	}
+	return nil
+}
+
+// containsClass reports whether the password contains a character of the given class
+func containsClass(password string, class frkrv1.CharacterClass) bool {
+	for _, r := range password {
+		switch class {
+		case frkrv1.CharacterClassUpper:
+			if unicode.IsUpper(r) {
+				return true
+			}
+		case frkrv1.CharacterClassLower:
+			if unicode.IsLower(r) {
+				return true
+			}
+		case frkrv1.CharacterClassDigit:
+			if unicode.IsDigit(r) {
+				return true
+			}
+		case frkrv1.CharacterClassSymbol:
+			// Whitespace and control characters do not count as symbols
+			if unicode.IsPunct(r) || unicode.IsSymbol(r) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// generatePassword generates a random password that satisfies the policy
+func generatePassword(policy *frkrv1.PasswordPolicy) (string, error) {
+	for attempt := 0; attempt < maxPasswordGenerationAttempts; attempt++ {
+		password, err := util.GeneratePassword()
+		if err != nil {
+			return "", err
+		}
+		for policy != nil && len(password) < policy.MinLength {
+			more, err := util.GeneratePassword()
+			if err != nil {
+				return "", err
+			}
+			password += more
+		}
+		if validatePassword(policy, password) == nil {
+			return password, nil
+		}
+	}
+	return "", fmt.Errorf("failed to generate a password satisfying the password policy")
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"testing"
+
+	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
+)
+
+func TestValidatePassword(t *testing.T) {
+	policy := &frkrv1.PasswordPolicy{
+		MinLength: 12,
+		RequiredClasses: []frkrv1.CharacterClass{
+			frkrv1.CharacterClassUpper,
+			frkrv1.CharacterClassLower,
+			frkrv1.CharacterClassDigit,
+			frkrv1.CharacterClassSymbol,
+		},
+		DisallowedWords: []string{"frkr", "password"},
+	}
+
+	tests := []struct {
+		name     string
+		policy   *frkrv1.PasswordPolicy
+		password string
+		wantErr  bool
+	}{
+		{name: "no policy", policy: nil, password: "x", wantErr: false},
+		{name: "compliant", policy: policy, password: "Correct-Horse-42", wantErr: false},
+		{name: "too short", policy: policy, password: "Sh0rt-Pw", wantErr: true},
+		{name: "missing digit", policy: policy, password: "Correct-Horse-Battery", wantErr: true},
+		{name: "missing symbol", policy: policy, password: "CorrectHorse42", wantErr: true},
+		{name: "space is not a symbol", policy: policy, password: "Correct Horse42", wantErr: true},
+		{name: "tab is not a symbol", policy: policy, password: "Correct\tHorse42", wantErr: true},
+		{name: "unicode symbol", policy: policy, password: "CorrectHorse42€", wantErr: false},
+		{name: "missing upper", policy: policy, password: "correct-horse-42", wantErr: true},
+		{name: "disallowed word any case", policy: policy, password: "My-FRKR-Secret-42", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePassword(tt.policy, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validatePassword(%q) error = %v, wantErr %v", tt.password, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGeneratePassword_SatisfiesPolicy(t *testing.T) {
+	policy := &frkrv1.PasswordPolicy{
+		MinLength: 64,
+		RequiredClasses: []frkrv1.CharacterClass{
+			frkrv1.CharacterClassUpper,
+			frkrv1.CharacterClassDigit,
+			frkrv1.CharacterClassSymbol,
+		},
+	}
+
+	for i := 0; i < 10; i++ {
+		password, err := generatePassword(policy)
+		if err != nil {
+			t.Fatalf("generatePassword() error = %v", err)
+		}
+		if err := validatePassword(policy, password); err != nil {
+			t.Errorf("generated password violates policy: %v", err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
+)
+
+// readSecretRef returns the value stored under the referenced key. If the Secret or key
+// is missing, it returns a description of the problem instead of an error so the caller
+// can report it and wait for the Secret to appear.
+func readSecretRef(ctx context.Context, c client.Client, namespace string, ref *frkrv1.SecretKeyReference, defaultKey string) (value, problem string, err error) {
+	key := ref.Key
+	if key == "" {
+		key = defaultKey
+	}
+
+	var secret corev1.Secret
+	if err := c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, &secret); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return "", "", fmt.Errorf("failed to get secret %s: %w", ref.Name, err)
+		}
+		return "", fmt.Sprintf("Secret %s not found", ref.Name), nil
+	}
+
+	data, ok := secret.Data[key]
+	if !ok || len(data) == 0 {
+		return "", fmt.Sprintf("Secret %s has no %q key", ref.Name, key), nil
+	}
+	return string(data), "", nil
+}
+
+// setSecretRefCondition reports whether the referenced Secret could be read
+func setSecretRefCondition(conditions *[]metav1.Condition, ref *frkrv1.SecretKeyReference, problem string) {
+	if ref == nil {
+		meta.RemoveStatusCondition(conditions, "SecretRefResolved")
+		return
+	}
+	if problem != "" {
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:    "SecretRefResolved",
+			Status:  metav1.ConditionFalse,
+			Reason:  "SecretNotReady",
+			Message: problem,
+		})
+		return
+	}
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:    "SecretRefResolved",
+		Status:  metav1.ConditionTrue,
+		Reason:  "SecretRead",
+		Message: fmt.Sprintf("Credential read from Secret %s", ref.Name),
+	})
+}
+
+// setInlineCredentialCondition warns while a deprecated inline credential field is in use
+func setInlineCredentialCondition(conditions *[]metav1.Condition, inlineField, refField string, inUse bool) {
+	if !inUse {
+		meta.RemoveStatusCondition(conditions, "InlineCredentialDeprecated")
+		return
+	}
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:    "InlineCredentialDeprecated",
+		Status:  metav1.ConditionTrue,
+		Reason:  "DeprecatedField",
+		Message: fmt.Sprintf("spec.%s is deprecated and stores the credential in plaintext; use spec.%s instead", inlineField, refField),
+	})
+}
@@ -2,31 +2,53 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
-	"github.com/frkr-io/frkr-common/util"
 	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
 	"github.com/frkr-io/frkr-operator/internal/infra"
 )
 
+// userFinalizer guards removal of the database user when a FrkrUser is deleted
+const userFinalizer = "frkr.io/user-cleanup"
+
 // UserReconciler reconciles a FrkrUser object
 type UserReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
-	DB     *infra.DB
+	Scheme   *runtime.Scheme
+	DB       *infra.DB
+	Recorder record.EventRecorder
+
+	// SecretNameTemplate names credential Secrets; DefaultUserSecretNameTemplate if nil
+	SecretNameTemplate *template.Template
 }
 
 //+kubebuilder:rbac:groups=frkr.io,resources=frkrusers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=frkr.io,resources=frkrusers/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=frkr.io,resources=frkrusers/finalizers,verbs=update
+//+kubebuilder:rbac:groups=frkr.io,resources=frkrroles,verbs=get;list;watch
+//+kubebuilder:rbac:groups=frkr.io,resources=frkrauthconfigs,verbs=get;list;watch
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop
 func (r *UserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -37,45 +59,236 @@ func (r *UserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	// Generate password if not provided
-	password := user.Spec.Password
+	if !user.DeletionTimestamp.IsZero() {
+		return r.finalizeUser(ctx, &user)
+	}
+	if controllerutil.AddFinalizer(&user, userFinalizer) {
+		if err := r.Update(ctx, &user); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to add finalizer: %w", err)
+		}
+	}
+
+	// Refuse to manage a (tenant, username) pair that another FrkrUser already manages
+	owner, err := r.conflictingUser(ctx, &user)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if owner != nil {
+		msg := fmt.Sprintf("Username %s in tenant %s is already managed by FrkrUser %s/%s", user.Spec.Username, user.Spec.TenantID, owner.Namespace, owner.Name)
+		if !meta.IsStatusConditionTrue(user.Status.Conditions, "UsernameConflict") {
+			r.Recorder.Event(&user, corev1.EventTypeWarning, "UsernameConflict", msg)
+		}
+		user.Status.Phase = "Conflict"
+		meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+			Type:    "UsernameConflict",
+			Status:  metav1.ConditionTrue,
+			Reason:  "DuplicateUsername",
+			Message: msg,
+		})
+		if err := r.Status().Update(ctx, &user); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+	meta.RemoveStatusCondition(&user.Status.Conditions, "UsernameConflict")
+
+	// Move credentials from a previously used Secret name before looking them up
+	secretName, err := r.credentialsSecretName(&user)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.migrateCredentialsSecret(ctx, &user, secretName); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Look up the credentials secret to find the password currently in use
+	existingSecret := &corev1.Secret{}
+	secretExists := true
+	if err := r.Get(ctx, client.ObjectKey{Name: secretName, Namespace: req.Namespace}, existingSecret); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to check for existing secret: %w", err)
+		}
+		secretExists = false
+	}
+	// Never read or overwrite credentials another object manages under the same name
+	if secretExists && !metav1.IsControlledBy(existingSecret, &user) {
+		return r.reportSecretConflict(ctx, &user, secretName)
+	}
+	meta.RemoveStatusCondition(&user.Status.Conditions, "SecretConflict")
+	currentPassword := string(existingSecret.Data["password"])
+
+	// The credentials secret is only written once the user has been persisted
+	persisted := secretExists
+
+	// Resolve the user-supplied password: a referenced Secret wins over the deprecated inline field
+	suppliedPassword := user.Spec.Password
+	setInlineCredentialCondition(&user.Status.Conditions, "password", "passwordSecretRef", user.Spec.Password != "")
+	if ref := user.Spec.PasswordSecretRef; ref != nil {
+		value, problem, err := readSecretRef(ctx, r.Client, user.Namespace, ref, "password")
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		setSecretRefCondition(&user.Status.Conditions, ref, problem)
+		if problem != "" {
+			// The Secret watch triggers a new reconcile once the Secret is fixed
+			logger.Info("waiting for password secret", "username", user.Spec.Username, "reason", problem)
+			if !persisted {
+				user.Status.Phase = "Pending"
+			}
+			if err := r.Status().Update(ctx, &user); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, nil
+		}
+		suppliedPassword = value
+	} else {
+		setSecretRefCondition(&user.Status.Conditions, nil, "")
+	}
+
+	// A reset is pending until the requested generation has been observed. A referenced
+	// password can only be changed in its Secret, so the request is recorded and rejected.
+	resetRequested := user.Spec.PasswordResetGeneration > user.Status.ObservedPasswordResetGeneration
+	if ref := user.Spec.PasswordSecretRef; ref != nil && resetRequested {
+		resetRequested = false
+		user.Status.ObservedPasswordResetGeneration = user.Spec.PasswordResetGeneration
+		message := fmt.Sprintf("The password is read from Secret %s; change it there", ref.Name)
+		meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+			Type:    "PasswordResetRejected",
+			Status:  metav1.ConditionTrue,
+			Reason:  "PasswordFromSecret",
+			Message: message,
+		})
+		r.Recorder.Eventf(&user, corev1.EventTypeWarning, "PasswordResetRejected", "Password reset for user %s rejected: %s", user.Spec.Username, message)
+	} else if ref == nil {
+		meta.RemoveStatusCondition(&user.Status.Conditions, "PasswordResetRejected")
+	}
+
+	// Generated passwords are rotated once they exceed the rotation policy's max age
+	now := time.Now()
+	rotationDue := false
+	if suppliedPassword == "" && currentPassword != "" && validRotationPolicy(&user) {
+		_, dueAt := rotationSchedule(&user, now)
+		rotationDue = !now.Before(dueAt)
+	}
+
+	// Resolve password: a supplied password wins, then the current password unless a reset or rotation is due
+	password := suppliedPassword
+	if password == "" && !resetRequested && !rotationDue {
+		password = currentPassword
+	}
+	policy, err := findPasswordPolicy(ctx, r.Client, user.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	generated := false
 	if password == "" {
-		// Generate random password using shared utility
-		var err error
-		password, err = util.GeneratePassword()
+		// Generate random password satisfying the namespace's password policy
+		password, err = generatePassword(policy)
 		if err != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to generate password: %w", err)
 		}
-		user.Status.PasswordGenerated = true
+		generated = true
 	}
+	passwordChanged := password != currentPassword
 
-	// Set password in status (one-time display only)
-	if user.Status.Password == "" {
-		user.Status.Password = password
-		user.Status.Phase = "Active"
+	// Reject user-supplied passwords that violate the policy, keeping any current password in place
+	if passwordChanged && !generated {
+		violation, err := r.checkPasswordPolicy(&user, policy, password, persisted)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if violation != "" {
+			logger.Info("password rejected by password policy", "username", user.Spec.Username, "reason", violation)
+			if !persisted {
+				user.Status.Phase = "Rejected"
+			}
+			meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+				Type:    "PasswordPolicyViolation",
+				Status:  metav1.ConditionTrue,
+				Reason:  "PasswordRejected",
+				Message: violation,
+			})
+			if err := r.Status().Update(ctx, &user); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, nil
+		}
+	}
+	if policy != nil {
+		meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+			Type:    "PasswordPolicyViolation",
+			Status:  metav1.ConditionFalse,
+			Reason:  "PasswordCompliant",
+			Message: "Password satisfies the password policy",
+		})
+	} else {
+		meta.RemoveStatusCondition(&user.Status.Conditions, "PasswordPolicyViolation")
 	}
 
+	// Resolve the permissions granted by the user's roles
+	grants, unresolved, err := r.resolveRoles(ctx, &user)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	permissions := effectivePermissions(grants)
+	appliedRoles := grantedRoles(grants)
+	// Role names are stored with each grant, so moving a user to an equivalent role also counts
+	rolesChanged := !slices.Equal(permissions, user.Status.EffectivePermissions) || !slices.Equal(appliedRoles, user.Status.AppliedRoles)
+
+	// Resolve whether access is currently revoked
+	phase, lockReason, lockedUntil := userLockState(&user, now)
+	lockChanged := phase != user.Status.Phase || lockReason != user.Status.LockReason || !lockedUntil.Equal(user.Status.LockedUntil)
+
 	// Step 1: Ensure tenant exists
-	if r.DB != nil {
+	if r.DB != nil && (!persisted || passwordChanged || rolesChanged || lockChanged) {
 		tenantID, err := r.DB.EnsureTenant(user.Spec.TenantID)
 		if err != nil {
 			logger.Error(err, "failed to ensure tenant")
 			return ctrl.Result{RequeueAfter: 30}, err
 		}
 
-		// Step 2: Persist user in database
-		// NOTE: In a real app, you'd hash the password here if using Postgres for auth.
-		// For now, the gateways accept any non-empty password as per current refactored code.
-		if err := r.DB.EnsureUser(tenantID, user.Spec.Username, password); err != nil {
-			logger.Error(err, "failed to persist user in database")
-			return ctrl.Result{RequeueAfter: 30}, err
+		// Step 2: Persist user in database (creates the user or replaces its password hash)
+		if !persisted || passwordChanged {
+			if !persisted && user.Annotations[frkrv1.UserPurgeRetainedAnnotation] == "true" {
+				if err := r.DB.PurgeRetainedUser(tenantID, user.Spec.Username); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+			err := r.DB.EnsureUser(tenantID, user.Spec.Username, password)
+			if errors.Is(err, infra.ErrUserRetained) {
+				return r.reportRetainedUser(ctx, &user)
+			}
+			if err != nil {
+				logger.Error(err, "failed to persist user in database")
+				return ctrl.Result{RequeueAfter: 30}, err
+			}
+		}
+
+		// Step 3: Persist role assignments
+		if !persisted || rolesChanged {
+			if err := r.DB.SetUserRoles(tenantID, user.Spec.Username, grants); err != nil {
+				logger.Error(err, "failed to persist user roles in database")
+				return ctrl.Result{RequeueAfter: 30}, err
+			}
+		}
+
+		// Step 4: Persist disabled/locked state so gateways refuse authentication
+		if !persisted || lockChanged {
+			var until *time.Time
+			if user.Spec.LockedUntil != nil {
+				until = &user.Spec.LockedUntil.Time
+			}
+			if err := r.DB.SetUserLock(tenantID, user.Spec.Username, user.Spec.Disabled, until, lockReason); err != nil {
+				logger.Error(err, "failed to persist user lock in database")
+				return ctrl.Result{RequeueAfter: 30}, err
+			}
 		}
 	}
 
 	// Create Kubernetes secret for credentials
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("frkr-user-%s", user.Spec.Username),
+			Name:      secretName,
 			Namespace: req.Namespace,
 		},
 		Data: map[string][]byte{
@@ -89,36 +302,441 @@ func (r *UserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	}
 
 	// Create or update secret
-	existingSecret := &corev1.Secret{}
-	err := r.Get(ctx, client.ObjectKeyFromObject(secret), existingSecret)
-	if err != nil {
-		if client.IgnoreNotFound(err) != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to check for existing secret: %w", err)
-		}
-		// Secret doesn't exist, create it
+	if !secretExists {
 		if err := r.Create(ctx, secret); err != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to create secret: %w", err)
 		}
 	} else {
-		// Secret exists, update it
 		existingSecret.Data = secret.Data
 		if err := r.Update(ctx, existingSecret); err != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to update secret: %w", err)
 		}
 	}
 
+	// Set password in status (one-time display only); referenced passwords stay in their Secret
+	if user.Spec.PasswordSecretRef != nil {
+		user.Status.Password = ""
+		user.Status.PasswordGenerated = false
+	} else if passwordChanged {
+		user.Status.Password = password
+		user.Status.PasswordGenerated = generated
+	}
+	if resetRequested || rotationDue {
+		resetAt := metav1.NewTime(now)
+		user.Status.LastPasswordReset = &resetAt
+	}
+	if resetRequested {
+		user.Status.ObservedPasswordResetGeneration = user.Spec.PasswordResetGeneration
+		r.Recorder.Eventf(&user, corev1.EventTypeNormal, "PasswordReset", "Password for user %s was reset", user.Spec.Username)
+	} else if rotationDue {
+		r.Recorder.Eventf(&user, corev1.EventTypeNormal, "PasswordRotated", "Password for user %s was rotated", user.Spec.Username)
+	}
+	if lockChanged && persisted {
+		switch phase {
+		case "Disabled":
+			r.Recorder.Eventf(&user, corev1.EventTypeNormal, "UserDisabled", "User %s disabled: %s", user.Spec.Username, lockReason)
+		case "Locked":
+			r.Recorder.Eventf(&user, corev1.EventTypeNormal, "UserLocked", "User %s locked until %s: %s", user.Spec.Username, lockedUntil.UTC().Format(time.RFC3339), lockReason)
+		default:
+			r.Recorder.Eventf(&user, corev1.EventTypeNormal, "UserEnabled", "User %s can authenticate again", user.Spec.Username)
+		}
+	}
+	user.Status.Phase = phase
+	user.Status.CredentialsSecret = secretName
+	user.Status.LockReason = lockReason
+	user.Status.LockedUntil = lockedUntil
+	user.Status.EffectivePermissions = permissions
+	user.Status.AppliedRoles = appliedRoles
+	setRolesResolvedCondition(&user, unresolved)
+	requeueAfter := r.updateRotationStatus(&user, now)
+	if lockedUntil != nil && (requeueAfter == 0 || lockedUntil.Sub(now) < requeueAfter) {
+		// Re-enable the user once the lock expires
+		requeueAfter = lockedUntil.Sub(now)
+	}
+
 	// Update status
 	if err := r.Status().Update(ctx, &user); err != nil {
 		return ctrl.Result{}, err
 	}
 
 	logger.Info("reconciled user", "username", user.Spec.Username)
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// reportSecretConflict refuses to use a credentials Secret the user does not control
+func (r *UserReconciler) reportSecretConflict(ctx context.Context, user *frkrv1.FrkrUser, secretName string) (ctrl.Result, error) {
+	msg := fmt.Sprintf("Secret %s already exists and is not controlled by this FrkrUser", secretName)
+	if !meta.IsStatusConditionTrue(user.Status.Conditions, "SecretConflict") {
+		r.Recorder.Event(user, corev1.EventTypeWarning, "SecretConflict", msg)
+	}
+	user.Status.Phase = "Conflict"
+	meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+		Type:    "SecretConflict",
+		Status:  metav1.ConditionTrue,
+		Reason:  "NotControlled",
+		Message: msg,
+	})
+	if err := r.Status().Update(ctx, user); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// reportRetainedUser refuses to take over a database record retained by an earlier deletion
+// and reports a conflict until the record is purged
+func (r *UserReconciler) reportRetainedUser(ctx context.Context, user *frkrv1.FrkrUser) (ctrl.Result, error) {
+	msg := fmt.Sprintf("Username %s in tenant %s belongs to a deleted user retained for audit; set annotation %s=true to purge the retained record",
+		user.Spec.Username, user.Spec.TenantID, frkrv1.UserPurgeRetainedAnnotation)
+	if user.Status.Phase != "Conflict" {
+		r.Recorder.Event(user, corev1.EventTypeWarning, "UsernameConflict", msg)
+	}
+	user.Status.Phase = "Conflict"
+	meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+		Type:    "UsernameConflict",
+		Status:  metav1.ConditionTrue,
+		Reason:  "RetainedRecord",
+		Message: msg,
+	})
+	if err := r.Status().Update(ctx, user); err != nil {
+		return ctrl.Result{}, err
+	}
 	return ctrl.Result{}, nil
 }
 
+// checkPasswordPolicy describes why a user-supplied password does not satisfy the policy
+// or reuses one of the user's recent passwords, returning "" if it is acceptable
+func (r *UserReconciler) checkPasswordPolicy(user *frkrv1.FrkrUser, policy *frkrv1.PasswordPolicy, password string, persisted bool) (string, error) {
+	if policy == nil {
+		return "", nil
+	}
+	if violation := validatePassword(policy, password); violation != nil {
+		return violation.Error(), nil
+	}
+
+	if policy.HistoryDepth > 0 && persisted && r.DB != nil {
+		tenantID, err := r.DB.GetTenantID(user.Spec.TenantID)
+		if err != nil {
+			return "", err
+		}
+		if tenantID != "" {
+			reused, err := r.DB.PasswordInHistory(tenantID, user.Spec.Username, password, policy.HistoryDepth)
+			if err != nil {
+				return "", err
+			}
+			if reused {
+				return fmt.Sprintf("password matches one of the last %d passwords", policy.HistoryDepth), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// userLockState returns the user's phase along with the reason and expiry of any lock
+func userLockState(user *frkrv1.FrkrUser, now time.Time) (phase, reason string, lockedUntil *metav1.Time) {
+	if user.Spec.Disabled {
+		reason = user.Spec.LockReason
+		if reason == "" {
+			reason = "Disabled by administrator"
+		}
+		return "Disabled", reason, nil
+	}
+	if user.Spec.LockedUntil != nil && now.Before(user.Spec.LockedUntil.Time) {
+		reason = user.Spec.LockReason
+		if reason == "" {
+			reason = "Locked by administrator"
+		}
+		return "Locked", reason, user.Spec.LockedUntil.DeepCopy()
+	}
+	return "Active", "", nil
+}
+
+// finalizeUser removes (or, with the Retain policy, disables) the database user
+// before letting Kubernetes delete the FrkrUser and its owned secret
+func (r *UserReconciler) finalizeUser(ctx context.Context, user *frkrv1.FrkrUser) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if !controllerutil.ContainsFinalizer(user, userFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	// A user that lost a (tenant, username) conflict never managed the database user
+	owner, err := r.conflictingUser(ctx, user)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if r.DB != nil && owner == nil {
+		tenantID, err := r.DB.GetTenantID(user.Spec.TenantID)
+		if err != nil {
+			logger.Error(err, "failed to look up tenant")
+			return ctrl.Result{}, err
+		}
+		if tenantID != "" {
+			keepRecord := user.Spec.DeletionPolicy == frkrv1.UserDeletionPolicyRetain
+			if err := r.DB.DeleteUser(tenantID, user.Spec.Username, keepRecord); err != nil {
+				logger.Error(err, "failed to remove user from database")
+				r.Recorder.Eventf(user, corev1.EventTypeWarning, "DeleteFailed", "Failed to remove database user: %v", err)
+				return ctrl.Result{}, err
+			}
+			if keepRecord {
+				r.Recorder.Eventf(user, corev1.EventTypeNormal, "UserRetained", "Database user %s disabled and retained for audit", user.Spec.Username)
+			} else {
+				r.Recorder.Eventf(user, corev1.EventTypeNormal, "UserDeleted", "Database user %s deleted", user.Spec.Username)
+			}
+		}
+	}
+
+	controllerutil.RemoveFinalizer(user, userFinalizer)
+	if err := r.Update(ctx, user); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to remove finalizer: %w", err)
+	}
+
+	logger.Info("finalized user", "username", user.Spec.Username)
+	return ctrl.Result{}, nil
+}
+
+// resolveRoles looks up the FrkrRoles referenced by the user and returns the grants they confer.
+// Roles that do not exist or belong to another tenant grant nothing and are returned as unresolved.
+func (r *UserReconciler) resolveRoles(ctx context.Context, user *frkrv1.FrkrUser) ([]infra.RoleGrant, []string, error) {
+	var grants []infra.RoleGrant
+	var unresolved []string
+
+	for _, name := range user.Spec.Roles {
+		var role frkrv1.FrkrRole
+		if err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: user.Namespace}, &role); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return nil, nil, fmt.Errorf("failed to get role %s: %w", name, err)
+			}
+			unresolved = append(unresolved, fmt.Sprintf("%s (not found)", name))
+			continue
+		}
+		if role.Spec.TenantID != user.Spec.TenantID {
+			unresolved = append(unresolved, fmt.Sprintf("%s (tenant %s)", name, role.Spec.TenantID))
+			continue
+		}
+
+		for _, perm := range role.Spec.Permissions {
+			if !perm.IsStreamScoped() || len(role.Spec.Streams) == 0 {
+				grants = append(grants, infra.RoleGrant{Role: name, Permission: string(perm)})
+				continue
+			}
+			for _, stream := range role.Spec.Streams {
+				grants = append(grants, infra.RoleGrant{Role: name, Permission: string(perm), Stream: stream})
+			}
+		}
+	}
+	return grants, unresolved, nil
+}
+
+// effectivePermissions flattens role grants into a sorted, de-duplicated permission list
+func effectivePermissions(grants []infra.RoleGrant) []string {
+	seen := make(map[string]bool)
+	var permissions []string
+	for _, g := range grants {
+		p := g.Permission
+		if g.Stream != "" {
+			p = fmt.Sprintf("%s:%s", g.Permission, g.Stream)
+		}
+		if !seen[p] {
+			seen[p] = true
+			permissions = append(permissions, p)
+		}
+	}
+	sort.Strings(permissions)
+	return permissions
+}
+
+// grantedRoles returns the sorted names of the roles that contribute grants
+func grantedRoles(grants []infra.RoleGrant) []string {
+	var roles []string
+	for _, g := range grants {
+		if !slices.Contains(roles, g.Role) {
+			roles = append(roles, g.Role)
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// setRolesResolvedCondition reports whether every referenced role could be applied
+func setRolesResolvedCondition(user *frkrv1.FrkrUser, unresolved []string) {
+	if len(user.Spec.Roles) == 0 {
+		meta.RemoveStatusCondition(&user.Status.Conditions, "RolesResolved")
+		return
+	}
+	if len(unresolved) > 0 {
+		meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+			Type:    "RolesResolved",
+			Status:  metav1.ConditionFalse,
+			Reason:  "RoleNotFound",
+			Message: fmt.Sprintf("Roles not applied: %s", strings.Join(unresolved, ", ")),
+		})
+		return
+	}
+	meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+		Type:    "RolesResolved",
+		Status:  metav1.ConditionTrue,
+		Reason:  "RolesResolved",
+		Message: fmt.Sprintf("%d role(s) applied", len(user.Spec.Roles)),
+	})
+}
+
+// validRotationPolicy reports whether the user has a usable rotation policy
+func validRotationPolicy(user *frkrv1.FrkrUser) bool {
+	return user.Spec.Rotation != nil && user.Spec.Rotation.MaxAge.Duration > 0
+}
+
+// rotationSchedule returns when the rotation warning starts and when the password must be rotated.
+// The password age is measured from the last reset, or from the user's creation if it was never reset.
+func rotationSchedule(user *frkrv1.FrkrUser, now time.Time) (warnAt, dueAt time.Time) {
+	setAt := user.CreationTimestamp.Time
+	if user.Status.LastPasswordReset != nil {
+		setAt = user.Status.LastPasswordReset.Time
+	}
+	if setAt.IsZero() {
+		setAt = now
+	}
+
+	dueAt = setAt.Add(user.Spec.Rotation.MaxAge.Duration)
+	warnAt = dueAt
+	if user.Spec.Rotation.NotifyBefore != nil {
+		warnAt = dueAt.Add(-user.Spec.Rotation.NotifyBefore.Duration)
+	}
+	return warnAt, dueAt
+}
+
+// updateRotationStatus sets the RotationDue condition from the rotation policy and
+// returns how long to wait until the next warning or rotation deadline
+func (r *UserReconciler) updateRotationStatus(user *frkrv1.FrkrUser, now time.Time) time.Duration {
+	if user.Spec.Rotation == nil {
+		meta.RemoveStatusCondition(&user.Status.Conditions, "RotationDue")
+		return 0
+	}
+	if !validRotationPolicy(user) {
+		meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+			Type:    "RotationDue",
+			Status:  metav1.ConditionFalse,
+			Reason:  "InvalidRotationPolicy",
+			Message: "rotation.maxAge must be greater than zero",
+		})
+		return 0
+	}
+
+	warnAt, dueAt := rotationSchedule(user, now)
+	if now.Before(warnAt) {
+		meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+			Type:    "RotationDue",
+			Status:  metav1.ConditionFalse,
+			Reason:  "PasswordCurrent",
+			Message: fmt.Sprintf("Password will be rotated at %s", dueAt.UTC().Format(time.RFC3339)),
+		})
+		return warnAt.Sub(now)
+	}
+
+	// Warn once when the condition becomes true
+	reason := "RotationApproaching"
+	msg := fmt.Sprintf("Password will be rotated at %s", dueAt.UTC().Format(time.RFC3339))
+	requeueAfter := dueAt.Sub(now)
+	if !now.Before(dueAt) {
+		// Only reachable when the password is supplied by the user and cannot be generated
+		reason = "InlinePassword"
+		msg = "Password exceeded rotation.maxAge but is user-supplied and cannot be rotated automatically"
+		requeueAfter = 0
+	}
+	if !meta.IsStatusConditionTrue(user.Status.Conditions, "RotationDue") {
+		r.Recorder.Event(user, corev1.EventTypeWarning, "RotationDue", msg)
+	}
+	meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+		Type:    "RotationDue",
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: msg,
+	})
+	return requeueAfter
+}
+
+// usersForRole maps a FrkrRole to the users in its namespace that reference it
+func (r *UserReconciler) usersForRole(ctx context.Context, obj client.Object) []reconcile.Request {
+	var userList frkrv1.FrkrUserList
+	if err := r.List(ctx, &userList, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list users for role", "role", obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, user := range userList.Items {
+		if slices.Contains(user.Spec.Roles, obj.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&user)})
+		}
+	}
+	return requests
+}
+
+// userPasswordSecretField indexes FrkrUsers by the name of the Secret in spec.passwordSecretRef
+const userPasswordSecretField = ".spec.passwordSecretRef.name"
+
+// indexUserPasswordSecret returns the index values of userPasswordSecretField
+func indexUserPasswordSecret(obj client.Object) []string {
+	user, ok := obj.(*frkrv1.FrkrUser)
+	if !ok || user.Spec.PasswordSecretRef == nil {
+		return nil
+	}
+	return []string{user.Spec.PasswordSecretRef.Name}
+}
+
+// usersForSecret maps a Secret to the users in its namespace whose password or credentials
+// it holds
+func (r *UserReconciler) usersForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+	for _, field := range []string{userPasswordSecretField, userCredentialsSecretField} {
+		var userList frkrv1.FrkrUserList
+		if err := r.List(ctx, &userList, client.InNamespace(obj.GetNamespace()),
+			client.MatchingFields{field: obj.GetName()}); err != nil {
+			log.FromContext(ctx).Error(err, "failed to list users for secret", "secret", obj.GetName())
+			return nil
+		}
+		for _, user := range userList.Items {
+			req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&user)}
+			if !slices.Contains(requests, req) {
+				requests = append(requests, req)
+			}
+		}
+	}
+	return requests
+}
+
+// usersForAuthConfig maps a FrkrAuthConfig to the users in its namespace, so a changed
+// password policy is applied to them
+func (r *UserReconciler) usersForAuthConfig(ctx context.Context, obj client.Object) []reconcile.Request {
+	var userList frkrv1.FrkrUserList
+	if err := r.List(ctx, &userList, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list users for auth config", "authConfig", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(userList.Items))
+	for _, user := range userList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&user)})
+	}
+	return requests
+}
+
 // SetupWithManager sets up the controller with the Manager
 func (r *UserReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &frkrv1.FrkrUser{}, userPasswordSecretField, indexUserPasswordSecret); err != nil {
+		return fmt.Errorf("failed to index users by password secret: %w", err)
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &frkrv1.FrkrUser{}, userCredentialsSecretField, r.indexCredentialsSecret); err != nil {
+		return fmt.Errorf("failed to index users by credentials secret: %w", err)
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &frkrv1.FrkrUser{}, userIdentityField, indexUserIdentity); err != nil {
+		return fmt.Errorf("failed to index users by tenant and username: %w", err)
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&frkrv1.FrkrUser{}).
+		Watches(&frkrv1.FrkrRole{}, handler.EnqueueRequestsFromMapFunc(r.usersForRole)).
+		Watches(&frkrv1.FrkrAuthConfig{}, handler.EnqueueRequestsFromMapFunc(r.usersForAuthConfig),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.usersForSecret)).
 		Complete(r)
 }
@@ -2,19 +2,29 @@ package controller
 
 import (
 	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/lib/pq"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
+	"github.com/frkr-io/frkr-operator/internal/naming"
 )
 
 var _ = Describe("UserReconciler", func() {
@@ -24,6 +34,7 @@ var _ = Describe("UserReconciler", func() {
 		reconciler *UserReconciler
 		fakeClient client.Client
 		scheme     *runtime.Scheme
+		recorder   *record.FakeRecorder
 	)
 
 	BeforeEach(func() {
@@ -35,11 +46,18 @@ var _ = Describe("UserReconciler", func() {
 		fakeClient = fake.NewClientBuilder().
 			WithScheme(scheme).
 			WithStatusSubresource(&frkrv1.FrkrUser{}).
+			WithIndex(&frkrv1.FrkrUser{}, userPasswordSecretField, indexUserPasswordSecret).
+			WithIndex(&frkrv1.FrkrUser{}, userCredentialsSecretField, func(obj client.Object) []string {
+				return reconciler.indexCredentialsSecret(obj)
+			}).
+			WithIndex(&frkrv1.FrkrUser{}, userIdentityField, indexUserIdentity).
 			Build()
 
+		recorder = record.NewFakeRecorder(10)
 		reconciler = &UserReconciler{
-			Client: fakeClient,
-			Scheme: scheme,
+			Client:   fakeClient,
+			Scheme:   scheme,
+			Recorder: recorder,
 		}
 	})
 
@@ -99,7 +117,7 @@ var _ = Describe("UserReconciler", func() {
 				// Verify secret was created
 				secret := &corev1.Secret{}
 				secretName := types.NamespacedName{
-					Name:      "frkr-user-testuser",
+					Name:      userSecretName("tenant-1", "testuser"),
 					Namespace: "default",
 				}
 				Expect(fakeClient.Get(ctx, secretName, secret)).To(Succeed())
@@ -125,6 +143,98 @@ var _ = Describe("UserReconciler", func() {
 				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
 				Expect(updated.Status.Password).To(Equal("provided-password"))
 				Expect(updated.Status.PasswordGenerated).To(BeFalse())
+				Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, "InlineCredentialDeprecated")).To(BeTrue())
+			})
+		})
+
+		Context("when the password is referenced from a secret", func() {
+			var req reconcile.Request
+
+			BeforeEach(func() {
+				Expect(fakeClient.Create(ctx, &frkrv1.FrkrUser{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-user",
+						Namespace: "default",
+					},
+					Spec: frkrv1.FrkrUserSpec{
+						Username:          "testuser",
+						TenantID:          "tenant-1",
+						PasswordSecretRef: &frkrv1.SecretKeyReference{Name: "testuser-password", Key: "value"},
+					},
+				})).To(Succeed())
+
+				req = reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      "test-user",
+						Namespace: "default",
+					},
+				}
+			})
+
+			It("should wait while the secret is missing", func() {
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				updated := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+				Expect(updated.Status.Phase).To(Equal("Pending"))
+				Expect(meta.IsStatusConditionFalse(updated.Status.Conditions, "SecretRefResolved")).To(BeTrue())
+			})
+
+			It("should use the referenced password and re-sync it when the secret changes", func() {
+				source := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "testuser-password", Namespace: "default"},
+					Data:       map[string][]byte{"value": []byte("first-password")},
+				}
+				Expect(fakeClient.Create(ctx, source)).To(Succeed())
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				updated := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+				Expect(updated.Status.Phase).To(Equal("Active"))
+				Expect(updated.Status.Password).To(BeEmpty())
+				Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, "SecretRefResolved")).To(BeTrue())
+				Expect(meta.FindStatusCondition(updated.Status.Conditions, "InlineCredentialDeprecated")).To(BeNil())
+
+				source.Data["value"] = []byte("second-password")
+				Expect(fakeClient.Update(ctx, source)).To(Succeed())
+				Expect(reconciler.usersForSecret(ctx, source)).To(ConsistOf(req))
+
+				_, err = reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				secret := &corev1.Secret{}
+				Expect(fakeClient.Get(ctx, types.NamespacedName{Name: userSecretName("tenant-1", "testuser"), Namespace: "default"}, secret)).To(Succeed())
+				Expect(string(secret.Data["password"])).To(Equal("second-password"))
+			})
+
+			It("should reject a password reset instead of recording one", func() {
+				Expect(fakeClient.Create(ctx, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "testuser-password", Namespace: "default"},
+					Data:       map[string][]byte{"value": []byte("first-password")},
+				})).To(Succeed())
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				user := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, user)).To(Succeed())
+				user.Spec.PasswordResetGeneration = 1
+				Expect(fakeClient.Update(ctx, user)).To(Succeed())
+				_, err = reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				updated := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+				Expect(updated.Status.LastPasswordReset).To(BeNil())
+				Expect(updated.Status.ObservedPasswordResetGeneration).To(Equal(int64(1)))
+				Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, "PasswordResetRejected")).To(BeTrue())
+				Expect(recorder.Events).To(Receive(ContainSubstring("PasswordResetRejected")))
+
+				secret := &corev1.Secret{}
+				Expect(fakeClient.Get(ctx, types.NamespacedName{Name: userSecretName("tenant-1", "testuser"), Namespace: "default"}, secret)).To(Succeed())
+				Expect(string(secret.Data["password"])).To(Equal("first-password"))
 			})
 		})
 
@@ -149,7 +259,7 @@ var _ = Describe("UserReconciler", func() {
 				// Create existing secret
 				secret = &corev1.Secret{
 					ObjectMeta: metav1.ObjectMeta{
-						Name:      "frkr-user-testuser",
+						Name:      userSecretName("tenant-1", "testuser"),
 						Namespace: "default",
 					},
 					Data: map[string][]byte{
@@ -157,6 +267,7 @@ var _ = Describe("UserReconciler", func() {
 						"password": []byte("old-password"),
 					},
 				}
+				Expect(controllerutil.SetControllerReference(user, secret, fakeClient.Scheme())).To(Succeed())
 				Expect(fakeClient.Create(ctx, secret)).To(Succeed())
 			})
 
@@ -177,7 +288,7 @@ var _ = Describe("UserReconciler", func() {
 				// Verify secret was updated
 				updatedSecret := &corev1.Secret{}
 				secretName := types.NamespacedName{
-					Name:      "frkr-user-testuser",
+					Name:      userSecretName("tenant-1", "testuser"),
 					Namespace: "default",
 				}
 				Expect(fakeClient.Get(ctx, secretName, updatedSecret)).To(Succeed())
@@ -185,6 +296,748 @@ var _ = Describe("UserReconciler", func() {
 			})
 		})
 
+		Context("when credential secrets are named per tenant", func() {
+			var req reconcile.Request
+
+			BeforeEach(func() {
+				req = reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      "tenant-1-admin",
+						Namespace: "default",
+					},
+				}
+			})
+
+			createUser := func(name, tenant string) *frkrv1.FrkrUser {
+				user := &frkrv1.FrkrUser{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: "default",
+					},
+					Spec: frkrv1.FrkrUserSpec{
+						Username: "admin",
+						TenantID: tenant,
+					},
+				}
+				Expect(fakeClient.Create(ctx, user)).To(Succeed())
+				return user
+			}
+
+			It("should keep the secrets of same-named users in different tenants apart", func() {
+				createUser("tenant-1-admin", "tenant-1")
+				createUser("tenant-2-admin", "tenant-2")
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "tenant-2-admin", Namespace: "default"}})
+				Expect(err).NotTo(HaveOccurred())
+
+				first := &corev1.Secret{}
+				Expect(fakeClient.Get(ctx, types.NamespacedName{Name: userSecretName("tenant-1", "admin"), Namespace: "default"}, first)).To(Succeed())
+				second := &corev1.Secret{}
+				Expect(fakeClient.Get(ctx, types.NamespacedName{Name: userSecretName("tenant-2", "admin"), Namespace: "default"}, second)).To(Succeed())
+				Expect(first.Data["password"]).NotTo(Equal(second.Data["password"]))
+
+				updated := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+				Expect(updated.Status.CredentialsSecret).To(Equal(userSecretName("tenant-1", "admin")))
+			})
+
+			It("should flag a second user with the same tenant and username", func() {
+				createUser("tenant-1-admin", "tenant-1")
+				createUser("tenant-1-admin-copy", "tenant-1")
+
+				copyReq := reconcile.Request{NamespacedName: types.NamespacedName{Name: "tenant-1-admin-copy", Namespace: "default"}}
+				_, err := reconciler.Reconcile(ctx, copyReq)
+				Expect(err).NotTo(HaveOccurred())
+
+				updated := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, copyReq.NamespacedName, updated)).To(Succeed())
+				Expect(updated.Status.Phase).To(Equal("Conflict"))
+				Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, "UsernameConflict")).To(BeTrue())
+				Expect(updated.Status.CredentialsSecret).To(BeEmpty())
+			})
+
+			It("should rename a legacy secret and keep its owner reference", func() {
+				user := createUser("tenant-1-admin", "tenant-1")
+				Expect(fakeClient.Get(ctx, req.NamespacedName, user)).To(Succeed())
+
+				legacy := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "frkr-user-admin",
+						Namespace: "default",
+					},
+					Data: map[string][]byte{
+						"username": []byte("admin"),
+						"password": []byte("legacy-password"),
+					},
+				}
+				Expect(ctrl.SetControllerReference(user, legacy, scheme)).To(Succeed())
+				Expect(fakeClient.Create(ctx, legacy)).To(Succeed())
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = fakeClient.Get(ctx, types.NamespacedName{Name: "frkr-user-admin", Namespace: "default"}, &corev1.Secret{})
+				Expect(client.IgnoreNotFound(err)).To(Succeed())
+				Expect(err).To(HaveOccurred())
+
+				renamed := &corev1.Secret{}
+				Expect(fakeClient.Get(ctx, types.NamespacedName{Name: userSecretName("tenant-1", "admin"), Namespace: "default"}, renamed)).To(Succeed())
+				Expect(string(renamed.Data["password"])).To(Equal("legacy-password"))
+				Expect(metav1.IsControlledBy(renamed, user)).To(BeTrue())
+				Expect(recorder.Events).To(Receive(ContainSubstring("SecretRenamed")))
+			})
+
+			It("should refuse a credentials secret it does not control", func() {
+				createUser("tenant-1-admin", "tenant-1")
+				foreign := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: userSecretName("tenant-1", "admin"), Namespace: "default"},
+					Data:       map[string][]byte{"password": []byte("someone-elses")},
+				}
+				Expect(fakeClient.Create(ctx, foreign)).To(Succeed())
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				updated := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+				Expect(updated.Status.Phase).To(Equal("Conflict"))
+				Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, "SecretConflict")).To(BeTrue())
+				Expect(updated.Status.Password).To(BeEmpty())
+				Expect(recorder.Events).To(Receive(ContainSubstring("SecretConflict")))
+
+				unchanged := &corev1.Secret{}
+				Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(foreign), unchanged)).To(Succeed())
+				Expect(unchanged.Data).To(Equal(foreign.Data))
+				Expect(unchanged.OwnerReferences).To(BeEmpty())
+
+				// Removing the Secret resolves the conflict
+				Expect(reconciler.usersForSecret(ctx, foreign)).To(ConsistOf(req))
+				Expect(fakeClient.Delete(ctx, foreign)).To(Succeed())
+				_, err = reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+				Expect(updated.Status.Phase).To(Equal("Active"))
+				Expect(meta.FindStatusCondition(updated.Status.Conditions, "SecretConflict")).To(BeNil())
+			})
+
+			It("should keep users whose tenant and username join to the same text apart", func() {
+				for _, u := range []struct{ name, tenant, username string }{
+					{"team-a-admin", "team-a", "admin"},
+					{"team-a-admin-2", "team", "a-admin"},
+				} {
+					Expect(fakeClient.Create(ctx, &frkrv1.FrkrUser{
+						ObjectMeta: metav1.ObjectMeta{Name: u.name, Namespace: "default"},
+						Spec:       frkrv1.FrkrUserSpec{Username: u.username, TenantID: u.tenant},
+					})).To(Succeed())
+					_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: u.name, Namespace: "default"}})
+					Expect(err).NotTo(HaveOccurred())
+
+					updated := &frkrv1.FrkrUser{}
+					Expect(fakeClient.Get(ctx, types.NamespacedName{Name: u.name, Namespace: "default"}, updated)).To(Succeed())
+					Expect(updated.Status.Phase).To(Equal("Active"))
+					Expect(updated.Status.CredentialsSecret).To(Equal(userSecretName(u.tenant, u.username)))
+				}
+			})
+
+			It("should use a configured secret name template", func() {
+				tmpl, err := ParseUserSecretNameTemplate("{{.Name}}-credentials")
+				Expect(err).NotTo(HaveOccurred())
+				reconciler.SecretNameTemplate = tmpl
+				createUser("tenant-1-admin", "tenant-1")
+
+				_, err = reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "tenant-1-admin-credentials", Namespace: "default"}, &corev1.Secret{})).To(Succeed())
+			})
+
+			It("should reject an invalid secret name template", func() {
+				_, err := ParseUserSecretNameTemplate("{{.Missing}}")
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when a password reset is requested", func() {
+			var req reconcile.Request
+
+			BeforeEach(func() {
+				user := &frkrv1.FrkrUser{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-user",
+						Namespace: "default",
+					},
+					Spec: frkrv1.FrkrUserSpec{
+						Username: "testuser",
+						TenantID: "tenant-1",
+					},
+				}
+				Expect(fakeClient.Create(ctx, user)).To(Succeed())
+
+				req = reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      "test-user",
+						Namespace: "default",
+					},
+				}
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should keep the password when no reset is pending", func() {
+				before := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, before)).To(Succeed())
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				after := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, after)).To(Succeed())
+				Expect(after.Status.Password).To(Equal(before.Status.Password))
+				Expect(after.Status.LastPasswordReset).To(BeNil())
+			})
+
+			It("should generate a new password and record the reset", func() {
+				before := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, before)).To(Succeed())
+				before.Spec.PasswordResetGeneration = 1
+				Expect(fakeClient.Update(ctx, before)).To(Succeed())
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				after := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, after)).To(Succeed())
+				Expect(after.Status.Password).NotTo(BeEmpty())
+				Expect(after.Status.Password).NotTo(Equal(before.Status.Password))
+				Expect(after.Status.LastPasswordReset).NotTo(BeNil())
+				Expect(after.Status.ObservedPasswordResetGeneration).To(Equal(int64(1)))
+
+				secret := &corev1.Secret{}
+				Expect(fakeClient.Get(ctx, types.NamespacedName{
+					Name:      userSecretName("tenant-1", "testuser"),
+					Namespace: "default",
+				}, secret)).To(Succeed())
+				Expect(string(secret.Data["password"])).To(Equal(after.Status.Password))
+
+				Expect(recorder.Events).To(Receive(ContainSubstring("PasswordReset")))
+			})
+		})
+
+		Context("when a rotation policy is configured", func() {
+			var req reconcile.Request
+
+			BeforeEach(func() {
+				user := &frkrv1.FrkrUser{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-user",
+						Namespace: "default",
+					},
+					Spec: frkrv1.FrkrUserSpec{
+						Username: "testuser",
+						TenantID: "tenant-1",
+						Rotation: &frkrv1.PasswordRotation{
+							MaxAge:       metav1.Duration{Duration: time.Hour},
+							NotifyBefore: &metav1.Duration{Duration: 10 * time.Minute},
+						},
+					},
+				}
+				Expect(fakeClient.Create(ctx, user)).To(Succeed())
+
+				req = reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      "test-user",
+						Namespace: "default",
+					},
+				}
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			setLastReset := func(ago time.Duration) *frkrv1.FrkrUser {
+				user := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, user)).To(Succeed())
+				resetAt := metav1.NewTime(time.Now().Add(-ago))
+				user.Status.LastPasswordReset = &resetAt
+				Expect(fakeClient.Status().Update(ctx, user)).To(Succeed())
+				return user
+			}
+
+			It("should requeue for the warning window while the password is current", func() {
+				setLastReset(time.Minute)
+
+				result, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically("~", 49*time.Minute, time.Minute))
+
+				updated := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+				Expect(meta.IsStatusConditionFalse(updated.Status.Conditions, "RotationDue")).To(BeTrue())
+			})
+
+			It("should set RotationDue ahead of the deadline", func() {
+				before := setLastReset(55 * time.Minute)
+
+				result, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically("<=", 5*time.Minute))
+
+				updated := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+				Expect(updated.Status.Password).To(Equal(before.Status.Password))
+				Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, "RotationDue")).To(BeTrue())
+				Expect(recorder.Events).To(Receive(ContainSubstring("RotationDue")))
+			})
+
+			It("should rotate the password once it exceeds the max age", func() {
+				before := setLastReset(2 * time.Hour)
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				updated := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+				Expect(updated.Status.Password).NotTo(Equal(before.Status.Password))
+				Expect(updated.Status.LastPasswordReset.Time).To(BeTemporally("~", time.Now(), time.Minute))
+				Expect(meta.IsStatusConditionFalse(updated.Status.Conditions, "RotationDue")).To(BeTrue())
+				Expect(recorder.Events).To(Receive(ContainSubstring("PasswordRotated")))
+			})
+		})
+
+		Context("when the user references roles", func() {
+			var req reconcile.Request
+
+			BeforeEach(func() {
+				roles := []*frkrv1.FrkrRole{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "reader", Namespace: "default"},
+						Spec: frkrv1.FrkrRoleSpec{
+							TenantID:    "tenant-1",
+							Permissions: []frkrv1.Permission{frkrv1.PermissionStreamRead, frkrv1.PermissionTenantRead},
+							Streams:     []string{"orders", "payments"},
+						},
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "other-tenant", Namespace: "default"},
+						Spec: frkrv1.FrkrRoleSpec{
+							TenantID:    "tenant-2",
+							Permissions: []frkrv1.Permission{frkrv1.PermissionTenantAdmin},
+						},
+					},
+				}
+				for _, role := range roles {
+					Expect(fakeClient.Create(ctx, role)).To(Succeed())
+				}
+
+				req = reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      "test-user",
+						Namespace: "default",
+					},
+				}
+			})
+
+			createUser := func(roles ...string) {
+				Expect(fakeClient.Create(ctx, &frkrv1.FrkrUser{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-user",
+						Namespace: "default",
+					},
+					Spec: frkrv1.FrkrUserSpec{
+						Username: "testuser",
+						TenantID: "tenant-1",
+						Roles:    roles,
+					},
+				})).To(Succeed())
+			}
+
+			It("should show the effective permissions in status", func() {
+				createUser("reader")
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				updated := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+				Expect(updated.Status.EffectivePermissions).To(Equal([]string{
+					"stream:read:orders",
+					"stream:read:payments",
+					"tenant:read",
+				}))
+				Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, "RolesResolved")).To(BeTrue())
+			})
+
+			It("should flag roles that are missing or belong to another tenant", func() {
+				createUser("reader", "missing", "other-tenant")
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				updated := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+				Expect(updated.Status.EffectivePermissions).NotTo(ContainElement("tenant:admin"))
+				cond := meta.FindStatusCondition(updated.Status.Conditions, "RolesResolved")
+				Expect(cond).NotTo(BeNil())
+				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				Expect(cond.Message).To(ContainSubstring("missing"))
+				Expect(cond.Message).To(ContainSubstring("other-tenant"))
+			})
+
+			It("should track the applied role names when switching to an equivalent role", func() {
+				createUser("reader")
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				reader := &frkrv1.FrkrRole{}
+				Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "reader", Namespace: "default"}, reader)).To(Succeed())
+				Expect(fakeClient.Create(ctx, &frkrv1.FrkrRole{
+					ObjectMeta: metav1.ObjectMeta{Name: "reader-copy", Namespace: "default"},
+					Spec:       reader.Spec,
+				})).To(Succeed())
+
+				user := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, user)).To(Succeed())
+				Expect(user.Status.AppliedRoles).To(Equal([]string{"reader"}))
+				permissions := user.Status.EffectivePermissions
+				user.Spec.Roles = []string{"reader-copy"}
+				Expect(fakeClient.Update(ctx, user)).To(Succeed())
+
+				_, err = reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeClient.Get(ctx, req.NamespacedName, user)).To(Succeed())
+				Expect(user.Status.EffectivePermissions).To(Equal(permissions))
+				Expect(user.Status.AppliedRoles).To(Equal([]string{"reader-copy"}))
+			})
+
+			It("should map role changes to the users that reference them", func() {
+				createUser("reader")
+
+				role := &frkrv1.FrkrRole{}
+				Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "reader", Namespace: "default"}, role)).To(Succeed())
+				Expect(reconciler.usersForRole(ctx, role)).To(ConsistOf(req))
+
+				Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "other-tenant", Namespace: "default"}, role)).To(Succeed())
+				Expect(reconciler.usersForRole(ctx, role)).To(BeEmpty())
+			})
+		})
+
+		Context("when the user is disabled or locked", func() {
+			var req reconcile.Request
+
+			BeforeEach(func() {
+				user := &frkrv1.FrkrUser{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-user",
+						Namespace: "default",
+					},
+					Spec: frkrv1.FrkrUserSpec{
+						Username: "testuser",
+						TenantID: "tenant-1",
+					},
+				}
+				Expect(fakeClient.Create(ctx, user)).To(Succeed())
+
+				req = reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      "test-user",
+						Namespace: "default",
+					},
+				}
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			updateSpec := func(mutate func(*frkrv1.FrkrUserSpec)) {
+				user := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, user)).To(Succeed())
+				mutate(&user.Spec)
+				Expect(fakeClient.Update(ctx, user)).To(Succeed())
+			}
+
+			It("should report a disabled user and re-enable it", func() {
+				updateSpec(func(spec *frkrv1.FrkrUserSpec) {
+					spec.Disabled = true
+					spec.LockReason = "on leave"
+				})
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				updated := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+				Expect(updated.Status.Phase).To(Equal("Disabled"))
+				Expect(updated.Status.LockReason).To(Equal("on leave"))
+				Expect(recorder.Events).To(Receive(ContainSubstring("UserDisabled")))
+
+				updateSpec(func(spec *frkrv1.FrkrUserSpec) {
+					spec.Disabled = false
+					spec.LockReason = ""
+				})
+				_, err = reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+				Expect(updated.Status.Phase).To(Equal("Active"))
+				Expect(updated.Status.LockReason).To(BeEmpty())
+				Expect(recorder.Events).To(Receive(ContainSubstring("UserEnabled")))
+			})
+
+			It("should lock the user and requeue at the lock expiry", func() {
+				until := metav1.NewTime(time.Now().Add(30 * time.Minute))
+				updateSpec(func(spec *frkrv1.FrkrUserSpec) {
+					spec.LockedUntil = &until
+				})
+
+				result, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically("~", 30*time.Minute, time.Minute))
+
+				updated := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+				Expect(updated.Status.Phase).To(Equal("Locked"))
+				Expect(updated.Status.LockedUntil).NotTo(BeNil())
+				Expect(updated.Status.LockReason).NotTo(BeEmpty())
+			})
+
+			It("should treat an expired lock as active", func() {
+				until := metav1.NewTime(time.Now().Add(-time.Minute))
+				updateSpec(func(spec *frkrv1.FrkrUserSpec) {
+					spec.LockedUntil = &until
+				})
+
+				result, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeZero())
+
+				updated := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+				Expect(updated.Status.Phase).To(Equal("Active"))
+				Expect(updated.Status.LockedUntil).To(BeNil())
+			})
+		})
+
+		Context("when the user is deleted", func() {
+			It("should add a finalizer and release it on deletion", func() {
+				user := &frkrv1.FrkrUser{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-user",
+						Namespace: "default",
+					},
+					Spec: frkrv1.FrkrUserSpec{
+						Username: "testuser",
+						TenantID: "tenant-1",
+					},
+				}
+				Expect(fakeClient.Create(ctx, user)).To(Succeed())
+
+				req := reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      "test-user",
+						Namespace: "default",
+					},
+				}
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				updated := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+				Expect(updated.Finalizers).To(ContainElement(userFinalizer))
+
+				Expect(fakeClient.Delete(ctx, updated)).To(Succeed())
+				_, err = reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = fakeClient.Get(ctx, req.NamespacedName, &frkrv1.FrkrUser{})
+				Expect(client.IgnoreNotFound(err)).To(Succeed())
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when a deleted user is retained in the database", func() {
+			var (
+				req    reconcile.Request
+				db     *fakeSQL
+				purged bool
+			)
+
+			BeforeEach(func() {
+				purged = false
+				reconciler.DB, db = newFakeDB(func(query string, _ []driver.NamedValue) (*fakeRows, error) {
+					now := time.Now()
+					switch {
+					case strings.Contains(query, "FROM tenants"):
+						return tenantRow("tenant-uuid"), nil
+					case strings.Contains(query, "INSERT INTO users") && !purged:
+						return nil, &pq.Error{Code: "23505"}
+					case strings.Contains(query, "INSERT INTO users"):
+						return &fakeRows{Rows: [][]driver.Value{{"user-uuid", "tenant-uuid", "testuser", "hash", now, now, nil}}}, nil
+					case strings.Contains(query, "SELECT deleted_at IS NOT NULL"):
+						return &fakeRows{Rows: [][]driver.Value{{true}}}, nil
+					case strings.Contains(query, "DELETE FROM users"):
+						purged = true
+					case strings.Contains(query, "SELECT id FROM users"):
+						return &fakeRows{Rows: [][]driver.Value{{"user-uuid"}}}, nil
+					}
+					return nil, nil
+				})
+				Expect(fakeClient.Create(ctx, &frkrv1.FrkrUser{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-user", Namespace: "default"},
+					Spec:       frkrv1.FrkrUserSpec{Username: "testuser", TenantID: "tenant-1"},
+				})).To(Succeed())
+				req = reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-user", Namespace: "default"}}
+			})
+
+			It("should refuse to reuse the retained record", func() {
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				updated := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+				Expect(updated.Status.Phase).To(Equal("Conflict"))
+				cond := meta.FindStatusCondition(updated.Status.Conditions, "UsernameConflict")
+				Expect(cond).NotTo(BeNil())
+				Expect(cond.Reason).To(Equal("RetainedRecord"))
+				Expect(db.Executed("deleted_at = NULL")).To(BeEmpty())
+				Expect(db.Executed("UPDATE users SET password_hash")).To(BeEmpty())
+				Expect(fakeClient.Get(ctx, types.NamespacedName{Name: userSecretName("tenant-1", "testuser"), Namespace: "default"}, &corev1.Secret{})).NotTo(Succeed())
+			})
+
+			It("should purge the retained record when asked to", func() {
+				updated := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+				updated.Annotations = map[string]string{frkrv1.UserPurgeRetainedAnnotation: "true"}
+				Expect(fakeClient.Update(ctx, updated)).To(Succeed())
+
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(purged).To(BeTrue())
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+				Expect(updated.Status.Phase).To(Equal("Active"))
+				Expect(meta.FindStatusCondition(updated.Status.Conditions, "UsernameConflict")).To(BeNil())
+			})
+		})
+
+		Context("when a password policy is configured", func() {
+			BeforeEach(func() {
+				Expect(fakeClient.Create(ctx, &frkrv1.FrkrAuthConfig{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "auth",
+						Namespace: "default",
+					},
+					Spec: frkrv1.FrkrAuthConfigSpec{
+						Type: frkrv1.AuthTypeBasic,
+						PasswordPolicy: &frkrv1.PasswordPolicy{
+							MinLength:       16,
+							RequiredClasses: []frkrv1.CharacterClass{frkrv1.CharacterClassDigit},
+						},
+					},
+				})).To(Succeed())
+			})
+
+			It("should reject a non-compliant user-supplied password", func() {
+				Expect(fakeClient.Create(ctx, &frkrv1.FrkrUser{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-user",
+						Namespace: "default",
+					},
+					Spec: frkrv1.FrkrUserSpec{
+						Username: "testuser",
+						TenantID: "tenant-1",
+						Password: "too-short",
+					},
+				})).To(Succeed())
+
+				req := reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      "test-user",
+						Namespace: "default",
+					},
+				}
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				updated := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+				Expect(updated.Status.Phase).To(Equal("Rejected"))
+				Expect(updated.Status.Password).To(BeEmpty())
+				Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, "PasswordPolicyViolation")).To(BeTrue())
+
+				err = fakeClient.Get(ctx, types.NamespacedName{Name: userSecretName("tenant-1", "testuser"), Namespace: "default"}, &corev1.Secret{})
+				Expect(client.IgnoreNotFound(err)).To(Succeed())
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should generate a compliant password", func() {
+				Expect(fakeClient.Create(ctx, &frkrv1.FrkrUser{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-user",
+						Namespace: "default",
+					},
+					Spec: frkrv1.FrkrUserSpec{
+						Username: "testuser",
+						TenantID: "tenant-1",
+					},
+				})).To(Succeed())
+
+				req := reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      "test-user",
+						Namespace: "default",
+					},
+				}
+				_, err := reconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+
+				updated := &frkrv1.FrkrUser{}
+				Expect(fakeClient.Get(ctx, req.NamespacedName, updated)).To(Succeed())
+				Expect(updated.Status.Phase).To(Equal("Active"))
+				Expect(len(updated.Status.Password)).To(BeNumerically(">=", 16))
+				Expect(updated.Status.Password).To(MatchRegexp(`[0-9]`))
+				Expect(meta.IsStatusConditionFalse(updated.Status.Conditions, "PasswordPolicyViolation")).To(BeTrue())
+			})
+
+			It("should apply the policy of the oldest auth config", func() {
+				existing := &frkrv1.FrkrAuthConfig{}
+				Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "auth", Namespace: "default"}, existing)).To(Succeed())
+				Expect(fakeClient.Create(ctx, &frkrv1.FrkrAuthConfig{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "aaa-older",
+						Namespace:         "default",
+						CreationTimestamp: metav1.NewTime(existing.CreationTimestamp.Add(-time.Hour)),
+					},
+					Spec: frkrv1.FrkrAuthConfigSpec{
+						Type:           frkrv1.AuthTypeBasic,
+						PasswordPolicy: &frkrv1.PasswordPolicy{MinLength: 8},
+					},
+				})).To(Succeed())
+
+				policy, err := findPasswordPolicy(ctx, fakeClient, "default")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(policy.MinLength).To(Equal(8))
+			})
+
+			It("should re-sync the namespace's users when an auth config changes", func() {
+				Expect(fakeClient.Create(ctx, &frkrv1.FrkrUser{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-user", Namespace: "default"},
+					Spec:       frkrv1.FrkrUserSpec{Username: "testuser", TenantID: "tenant-1"},
+				})).To(Succeed())
+				Expect(fakeClient.Create(ctx, &frkrv1.FrkrUser{
+					ObjectMeta: metav1.ObjectMeta{Name: "other-user", Namespace: "other"},
+					Spec:       frkrv1.FrkrUserSpec{Username: "other", TenantID: "tenant-1"},
+				})).To(Succeed())
+
+				requests := reconciler.usersForAuthConfig(ctx, &frkrv1.FrkrAuthConfig{
+					ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "default"},
+				})
+				Expect(requests).To(ConsistOf(reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: "test-user", Namespace: "default"},
+				}))
+			})
+		})
+
 		Context("when user does not exist", func() {
 			It("should not return an error", func() {
 				req := reconcile.Request{
@@ -239,12 +1092,14 @@ func BenchmarkUserReconciler_Reconcile(b *testing.B) {
 	fakeClient := fake.NewClientBuilder().
 		WithScheme(scheme).
 		WithStatusSubresource(&frkrv1.FrkrUser{}).
+		WithIndex(&frkrv1.FrkrUser{}, userIdentityField, indexUserIdentity).
 		WithObjects(user).
 		Build()
 
 	reconciler := &UserReconciler{
-		Client: fakeClient,
-		Scheme: scheme,
+		Client:   fakeClient,
+		Scheme:   scheme,
+		Recorder: &record.FakeRecorder{},
 	}
 
 	req := reconcile.Request{
@@ -261,3 +1116,8 @@ func BenchmarkUserReconciler_Reconcile(b *testing.B) {
 		_, _ = reconciler.Reconcile(ctx, req)
 	}
 }
+
+// userSecretName is the default credentials Secret name of a user
+func userSecretName(tenantID, username string) string {
+	return fmt.Sprintf("frkr-user-%s-%s-%s", tenantID, username, naming.PartsHash(tenantID, username))
+}
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	frkrv1 "github.com/frkr-io/frkr-operator/api/v1"
+	"github.com/frkr-io/frkr-operator/internal/naming"
+)
+
+// DefaultUserSecretNameTemplate names credential Secrets after the tenant and username,
+// so users with the same name in different tenants do not collide. The hash keeps pairs
+// like (team-a, admin) and (team, a-admin) apart.
+const DefaultUserSecretNameTemplate = "frkr-user-{{.Tenant}}-{{.Username}}-{{.Hash}}"
+
+var defaultUserSecretNameTemplate = template.Must(ParseUserSecretNameTemplate(DefaultUserSecretNameTemplate))
+
+// userSecretNameData is the data available to the credential Secret name template
+type userSecretNameData struct {
+	Tenant   string
+	Username string
+	Name     string
+	Hash     string
+}
+
+// ParseUserSecretNameTemplate parses a credential Secret name template.
+// The template can use {{.Tenant}}, {{.Username}}, {{.Name}} (the FrkrUser name) and
+// {{.Hash}} (a short hash of the tenant and username). Templates without {{.Hash}} can
+// render the same name for different users; the second one is then refused a Secret it
+// does not control.
+func ParseUserSecretNameTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("user-secret-name").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse user secret name template: %w", err)
+	}
+	if _, err := renderUserSecretName(tmpl, userSecretNameData{Tenant: "tenant", Username: "user", Name: "name", Hash: "0123abcd"}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// renderUserSecretName executes the template and sanitizes the result into a valid Secret name
+func renderUserSecretName(tmpl *template.Template, data userSecretNameData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render user secret name: %w", err)
+	}
+	name := naming.DNSName(buf.String())
+	if name == "" {
+		return "", fmt.Errorf("user secret name template rendered an empty name")
+	}
+	return name, nil
+}
+
+// credentialsSecretName returns the name of the Secret holding the user's credentials
+func (r *UserReconciler) credentialsSecretName(user *frkrv1.FrkrUser) (string, error) {
+	tmpl := r.SecretNameTemplate
+	if tmpl == nil {
+		tmpl = defaultUserSecretNameTemplate
+	}
+	return renderUserSecretName(tmpl, userSecretNameData{
+		Tenant:   user.Spec.TenantID,
+		Username: user.Spec.Username,
+		Name:     user.Name,
+		Hash:     naming.PartsHash(user.Spec.TenantID, user.Spec.Username),
+	})
+}
+
+// userCredentialsSecretField indexes FrkrUsers by the name of their credentials Secret
+const userCredentialsSecretField = ".credentialsSecret"
+
+// indexCredentialsSecret returns the index values of userCredentialsSecretField
+func (r *UserReconciler) indexCredentialsSecret(obj client.Object) []string {
+	user, ok := obj.(*frkrv1.FrkrUser)
+	if !ok {
+		return nil
+	}
+	name, err := r.credentialsSecretName(user)
+	if err != nil {
+		return nil
+	}
+	return []string{name}
+}
+
+// userIdentityField indexes FrkrUsers by a hash of their (tenant, username) pair
+const userIdentityField = ".spec.identity"
+
+// indexUserIdentity returns the index values of userIdentityField
+func indexUserIdentity(obj client.Object) []string {
+	user, ok := obj.(*frkrv1.FrkrUser)
+	if !ok {
+		return nil
+	}
+	return []string{naming.PartsHash(user.Spec.TenantID, user.Spec.Username)}
+}
+
+// migrateCredentialsSecret renames a credentials Secret created under an earlier name
+// (the legacy frkr-user-<username> or a previous template) to secretName. Only Secrets
+// controlled by this user are moved; labels, annotations and owner references are kept.
+func (r *UserReconciler) migrateCredentialsSecret(ctx context.Context, user *frkrv1.FrkrUser, secretName string) error {
+	logger := log.FromContext(ctx)
+
+	candidates := []string{user.Status.CredentialsSecret, fmt.Sprintf("frkr-user-%s", user.Spec.Username)}
+	for _, oldName := range candidates {
+		if oldName == "" || oldName == secretName {
+			continue
+		}
+
+		var old corev1.Secret
+		if err := r.Get(ctx, client.ObjectKey{Name: oldName, Namespace: user.Namespace}, &old); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to get secret %s: %w", oldName, err)
+			}
+			continue
+		}
+		if !metav1.IsControlledBy(&old, user) {
+			continue
+		}
+
+		var existing corev1.Secret
+		err := r.Get(ctx, client.ObjectKey{Name: secretName, Namespace: user.Namespace}, &existing)
+		if client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to check for secret %s: %w", secretName, err)
+		}
+		if err != nil {
+			renamed := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            secretName,
+					Namespace:       user.Namespace,
+					Labels:          old.Labels,
+					Annotations:     old.Annotations,
+					OwnerReferences: old.OwnerReferences,
+				},
+				Type: old.Type,
+				Data: old.Data,
+			}
+			if err := r.Create(ctx, renamed); err != nil {
+				return fmt.Errorf("failed to create secret %s: %w", secretName, err)
+			}
+		}
+
+		if err := r.Delete(ctx, &old); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete secret %s: %w", oldName, err)
+		}
+		logger.Info("renamed credentials secret", "username", user.Spec.Username, "from", oldName, "to", secretName)
+		r.Recorder.Eventf(user, corev1.EventTypeNormal, "SecretRenamed", "Credentials secret renamed from %s to %s", oldName, secretName)
+	}
+	return nil
+}
+
+// conflictingUser returns the FrkrUser that already manages the same (tenant, username)
+// pair, or nil if this user owns it. The oldest FrkrUser across all namespaces wins.
+func (r *UserReconciler) conflictingUser(ctx context.Context, user *frkrv1.FrkrUser) (*frkrv1.FrkrUser, error) {
+	var userList frkrv1.FrkrUserList
+	if err := r.List(ctx, &userList, client.MatchingFields{
+		userIdentityField: naming.PartsHash(user.Spec.TenantID, user.Spec.Username),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	for i := range userList.Items {
+		other := &userList.Items[i]
+		if (other.Namespace == user.Namespace && other.Name == user.Name) ||
+			other.Spec.TenantID != user.Spec.TenantID || other.Spec.Username != user.Spec.Username {
+			continue
+		}
+		if claimsBefore(other, user) {
+			return other, nil
+		}
+	}
+	return nil, nil
+}
+
+// claimsBefore reports whether a was created before b, breaking ties by namespace and name
+func claimsBefore(a, b *frkrv1.FrkrUser) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
@@ -1,17 +1,22 @@
 package infra
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"net"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	commondb "github.com/frkr-io/frkr-common/db"
 	"github.com/frkr-io/frkr-common/models"
-	_ "github.com/lib/pq"
+	"github.com/frkr-io/frkr-common/util"
+	"github.com/lib/pq"
 	"github.com/segmentio/kafka-go"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // ... (imports)
@@ -22,6 +27,9 @@ import (
 type InfraConfig struct {
 	DatabaseURL string
 	BrokerURL   string
+
+	// ClientSecretHash is the algorithm used to hash client secrets at rest
+	ClientSecretHash SecretHashAlgorithm
 }
 
 // GetConfigFromEnv reads infrastructure configuration from environment variables
@@ -36,15 +44,28 @@ func GetConfigFromEnv() (*InfraConfig, error) {
 		brokerURL = "frkr-redpanda:9092"
 	}
 
+	clientSecretHash, err := ParseSecretHashAlgorithm(os.Getenv("CLIENT_SECRET_HASH_ALGORITHM"))
+	if err != nil {
+		return nil, err
+	}
+
 	return &InfraConfig{
-		DatabaseURL: dbURL,
-		BrokerURL:   brokerURL,
+		DatabaseURL:      dbURL,
+		BrokerURL:        brokerURL,
+		ClientSecretHash: clientSecretHash,
 	}, nil
 }
 
 // DB wraps database operations
 type DB struct {
 	*sql.DB
+
+	// SecretHash is the algorithm used to hash client secrets. The zero value stores them in
+	// plaintext, which is what gateways on frkr-common v0.3.3 and earlier can verify.
+	SecretHash SecretHashAlgorithm
+
+	schemaMu    sync.Mutex
+	schemaReady bool
 }
 
 // ConnectInfraDB creates a new database connection
@@ -59,7 +80,7 @@ func ConnectInfraDB(connString string) (*DB, error) {
 	db.SetMaxIdleConns(5)
 	db.SetConnMaxLifetime(time.Hour)
 
-	return &DB{db}, nil
+	return &DB{DB: db}, nil
 }
 
 // EnsureTenant creates a tenant if it doesn't exist, returns tenant ID
@@ -71,6 +92,19 @@ func (db *DB) EnsureTenant(tenantName string) (string, error) {
 	return tenant.ID, nil
 }
 
+// GetTenantID looks up a tenant by name without creating it, returns "" if it does not exist
+func (db *DB) GetTenantID(tenantName string) (string, error) {
+	var id string
+	err := db.QueryRow(`SELECT id FROM tenants WHERE name = $1 AND deleted_at IS NULL`, tenantName).Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query tenant: %w", err)
+	}
+	return id, nil
+}
+
 // CreateStream creates a stream record in the database
 func (db *DB) CreateStream(tenantID, name, description string, retentionDays int) (streamID, topic string, err error) {
 	stream, err := commondb.CreateStream(db.DB, tenantID, name, description, retentionDays)
@@ -144,26 +178,635 @@ func (k *KafkaAdmin) CreateTopic(topicName string, numPartitions, replicationFac
 	return nil
 }
 
-// EnsureUser creates a user in the database
-// Note: This logic was previously placeholder, now using shared implementation
+// DeleteConsumerGroups removes consumer groups from the broker. Groups that do not
+// exist are ignored.
+func (k *KafkaAdmin) DeleteConsumerGroups(ctx context.Context, groupIDs ...string) error {
+	if len(groupIDs) == 0 {
+		return nil
+	}
+
+	c := &kafka.Client{Addr: kafka.TCP(k.brokerURL)}
+	resp, err := c.DeleteGroups(ctx, &kafka.DeleteGroupsRequest{GroupIDs: groupIDs})
+	if err != nil {
+		return fmt.Errorf("failed to delete consumer groups: %w", err)
+	}
+	for groupID, groupErr := range resp.Errors {
+		if groupErr == nil || errors.Is(groupErr, kafka.GroupIdNotFound) {
+			continue
+		}
+		return fmt.Errorf("failed to delete consumer group %s: %w", groupID, groupErr)
+	}
+	return nil
+}
+
+// ErrUserRetained is returned by EnsureUser when the username belongs to a record retained
+// for audit by an earlier deletion
+var ErrUserRetained = errors.New("a deleted user with this username is retained for audit")
+
+// EnsureUser creates a user in the database, or updates the password of an existing one.
+// A record retained by an earlier deletion is never reused, since it carries the old user's
+// roles, password history and lock state; ErrUserRetained is returned instead.
 func (db *DB) EnsureUser(tenantID, username, password string) error {
 	_, err := commondb.CreateUser(db.DB, tenantID, username, password)
+	if err != nil && strings.Contains(err.Error(), "already exists") {
+		var retained bool
+		if err := db.QueryRow(`
+			SELECT deleted_at IS NOT NULL FROM users WHERE tenant_id = $1 AND username = $2
+		`, tenantID, username).Scan(&retained); err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if retained {
+			return ErrUserRetained
+		}
+		return db.SetUserPassword(tenantID, username, password)
+	}
 	return err
 }
 
-// EnsureClient creates a client credential in the database, or retrieves it if it already exists
-func (db *DB) EnsureClient(tenantID, clientID, clientSecret string, streamID *string) (*models.ClientCredential, error) {
-	client, err := commondb.CreateClient(db.DB, tenantID, clientID, clientSecret, streamID)
+// PurgeRetainedUser deletes a user record retained by an earlier deletion. Active users are
+// left alone.
+func (db *DB) PurgeRetainedUser(tenantID, username string) error {
+	if _, err := db.Exec(`
+		DELETE FROM users WHERE tenant_id = $1 AND username = $2 AND deleted_at IS NOT NULL
+	`, tenantID, username); err != nil {
+		return fmt.Errorf("failed to purge retained user: %w", err)
+	}
+	return nil
+}
+
+// SetUserPassword replaces the password hash of an existing user, keeping the old hash in the password history
+func (db *DB) SetUserPassword(tenantID, username, password string) error {
+	if len(password) < 8 {
+		return fmt.Errorf("password must be at least 8 characters")
+	}
+	if err := db.EnsureSchema(); err != nil {
+		return err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO user_password_history (user_id, password_hash)
+		SELECT id, password_hash FROM users
+		WHERE tenant_id = $1 AND username = $2 AND deleted_at IS NULL AND password_hash <> ''
+	`, tenantID, username); err != nil {
+		return fmt.Errorf("failed to record password history: %w", err)
+	}
+
+	res, err := tx.Exec(`
+		UPDATE users SET password_hash = $1, updated_at = now()
+		WHERE tenant_id = $2 AND username = $3 AND deleted_at IS NULL
+	`, string(passwordHash), tenantID, username)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("user '%s' not found", username)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit password change: %w", err)
+	}
+	return nil
+}
+
+// PasswordInHistory reports whether password matches the user's current password
+// or one of the previous ones, looking at no more than depth passwords in total
+func (db *DB) PasswordInHistory(tenantID, username, password string, depth int) (bool, error) {
+	if depth <= 0 {
+		return false, nil
+	}
+	if err := db.EnsureSchema(); err != nil {
+		return false, err
+	}
+
+	rows, err := db.Query(`
+		SELECT password_hash FROM (
+			SELECT u.password_hash, u.updated_at AS changed_at FROM users u
+			WHERE u.tenant_id = $1 AND u.username = $2 AND u.deleted_at IS NULL
+			UNION ALL
+			SELECT h.password_hash, h.created_at AS changed_at FROM user_password_history h
+			JOIN users u ON u.id = h.user_id
+			WHERE u.tenant_id = $1 AND u.username = $2 AND u.deleted_at IS NULL
+		) passwords
+		ORDER BY changed_at DESC
+		LIMIT $3
+	`, tenantID, username, depth)
+	if err != nil {
+		return false, fmt.Errorf("failed to query password history: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return false, fmt.Errorf("failed to scan password history: %w", err)
+		}
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// SetUserLock records whether a user is disabled or locked so gateways refuse authentication
+func (db *DB) SetUserLock(tenantID, username string, disabled bool, lockedUntil *time.Time, reason string) error {
+	if err := db.EnsureSchema(); err != nil {
+		return err
+	}
+
+	res, err := db.Exec(`
+		UPDATE users SET disabled = $1, locked_until = $2, lock_reason = $3, updated_at = now()
+		WHERE tenant_id = $4 AND username = $5 AND deleted_at IS NULL
+	`, disabled, lockedUntil, reason, tenantID, username)
+	if err != nil {
+		return fmt.Errorf("failed to update user lock: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("user '%s' not found", username)
+	}
+	return nil
+}
+
+// DeleteUser removes a user from the database. With keepRecord the row is retained for
+// audit, but marked deleted and stripped of its password hash and roles so it can no longer
+// authenticate. Deleting a user that does not exist is not an error.
+func (db *DB) DeleteUser(tenantID, username string, keepRecord bool) error {
+	if !keepRecord {
+		if _, err := db.Exec(`DELETE FROM users WHERE tenant_id = $1 AND username = $2`, tenantID, username); err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+		return nil
+	}
+
+	if err := db.EnsureSchema(); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		DELETE FROM user_roles WHERE user_id IN (
+			SELECT id FROM users WHERE tenant_id = $1 AND username = $2
+		)
+	`, tenantID, username); err != nil {
+		return fmt.Errorf("failed to clear user roles: %w", err)
+	}
+	if _, err := tx.Exec(`
+		UPDATE users SET password_hash = '', deleted_at = now(), updated_at = now()
+		WHERE tenant_id = $1 AND username = $2 AND deleted_at IS NULL
+	`, tenantID, username); err != nil {
+		return fmt.Errorf("failed to disable user: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit user deletion: %w", err)
+	}
+	return nil
+}
+
+// RoleGrant is a single permission a user holds through a role, optionally limited to one stream
+type RoleGrant struct {
+	Role       string
+	Permission string
+	Stream     string
+}
+
+// SetUserRoles replaces the role grants of a user
+func (db *DB) SetUserRoles(tenantID, username string, grants []RoleGrant) error {
+	if err := db.EnsureSchema(); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
 	if err != nil {
-		if strings.Contains(err.Error(), "already exists") {
-			// Try to get existing client
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var userID string
+	if err := tx.QueryRow(`
+		SELECT id FROM users WHERE tenant_id = $1 AND username = $2 AND deleted_at IS NULL
+	`, tenantID, username).Scan(&userID); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("user '%s' not found", username)
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM user_roles WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to clear user roles: %w", err)
+	}
+	for _, g := range grants {
+		if _, err := tx.Exec(`
+			INSERT INTO user_roles (user_id, role, permission, stream)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT DO NOTHING
+		`, userID, g.Role, g.Permission, g.Stream); err != nil {
+			return fmt.Errorf("failed to insert user role: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit user roles: %w", err)
+	}
+	return nil
+}
+
+// ClientScope grants a client permissions on a single stream
+type ClientScope struct {
+	StreamID    string
+	Permissions []string
+}
+
+// EnsureClient creates a client credential in the database, or retrieves it if it already exists.
+// The secret is stored with db.SecretHash. The secret of an existing client is only replaced
+// when replaceSecret is set, so callers that know the stored secret is current skip the write
+// and any hash verification. The stream scopes are always replaced by scopes.
+// An empty clientSecret creates a client without a usable shared secret, for clients that
+// authenticate with a certificate.
+func (db *DB) EnsureClient(tenantID, clientID, clientSecret string, replaceSecret bool, streamID *string, scopes []ClientScope) (*models.ClientCredential, error) {
+	keepSecret := clientSecret == ""
+	if keepSecret {
+		unusable, err := util.GeneratePassword()
+		if err != nil {
+			return nil, err
+		}
+		clientSecret = unusable
+	}
+	if len(clientSecret) < 8 {
+		return nil, fmt.Errorf("client secret must be at least 8 characters")
+	}
+
+	created := false
+	client, err := commondb.GetClient(db.DB, tenantID, clientID)
+	if err != nil {
+		if !strings.Contains(err.Error(), "not found") {
+			return nil, fmt.Errorf("failed to get client: %w", err)
+		}
+		secretHash, err := hashSecret(db.SecretHash, clientSecret)
+		if err != nil {
+			return nil, err
+		}
+		client, err = commondb.CreateClient(db.DB, tenantID, clientID, secretHash, streamID)
+		if err != nil {
+			if !strings.Contains(err.Error(), "already exists") {
+				return nil, err
+			}
+			// Created concurrently; reconcile against the stored row
 			existing, getErr := commondb.GetClient(db.DB, tenantID, clientID)
 			if getErr != nil {
 				return nil, fmt.Errorf("failed to create client: %v, and failed to get existing: %v", err, getErr)
 			}
-			return existing, nil
+			client = existing
+		} else {
+			created = true
 		}
+	}
+
+	if !created && !keepSecret && replaceSecret {
+		secretHash, err := hashSecret(db.SecretHash, clientSecret)
+		if err != nil {
+			return nil, err
+		}
+		if err := db.setClientSecretHash(tenantID, clientID, secretHash); err != nil {
+			return nil, err
+		}
+		client.ClientSecret = secretHash
+	}
+
+	if err := db.setClientScopes(client.ID, scopes); err != nil {
 		return nil, err
 	}
 	return client, nil
 }
+
+// setClientScopes replaces the stream scopes of a client and lifts a suspension by
+// SuspendClient, since the scopes are complete again
+func (db *DB) setClientScopes(clientUUID string, scopes []ClientScope) error {
+	if err := db.EnsureSchema(); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM client_scopes WHERE client_id = $1`, clientUUID); err != nil {
+		return fmt.Errorf("failed to clear client scopes: %w", err)
+	}
+	for _, scope := range scopes {
+		for _, perm := range scope.Permissions {
+			if _, err := tx.Exec(`
+				INSERT INTO client_scopes (client_id, stream_id, permission)
+				VALUES ($1, $2, $3)
+				ON CONFLICT DO NOTHING
+			`, clientUUID, scope.StreamID, perm); err != nil {
+				return fmt.Errorf("failed to insert client scope: %w", err)
+			}
+		}
+	}
+
+	if _, err := tx.Exec(`UPDATE clients SET disabled = false WHERE id = $1 AND disabled`, clientUUID); err != nil {
+		return fmt.Errorf("failed to enable client: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit client scopes: %w", err)
+	}
+	return nil
+}
+
+// SuspendClient stops a client credential from authenticating until EnsureClient stores its
+// scopes again. Suspending a client that does not exist is not an error.
+func (db *DB) SuspendClient(tenantID, clientID string) error {
+	if err := db.EnsureSchema(); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`
+		UPDATE clients SET disabled = true, updated_at = now()
+		WHERE tenant_id = $1 AND client_id = $2 AND deleted_at IS NULL AND NOT disabled
+	`, tenantID, clientID); err != nil {
+		return fmt.Errorf("failed to suspend client: %w", err)
+	}
+	return nil
+}
+
+// RegisterClientCertificate records the fingerprint of a certificate issued to a client, so
+// gateways accept it until notAfter. Registrations of expired certificates are removed.
+func (db *DB) RegisterClientCertificate(tenantID, clientID, fingerprint, subject string, notAfter time.Time) error {
+	if err := db.EnsureSchema(); err != nil {
+		return err
+	}
+
+	res, err := db.Exec(`
+		INSERT INTO client_certificates (fingerprint, client_id, subject, not_after)
+		SELECT $1, id, $2, $3 FROM clients
+		WHERE tenant_id = $4 AND client_id = $5 AND deleted_at IS NULL
+		ON CONFLICT (fingerprint) DO NOTHING
+	`, fingerprint, subject, notAfter, tenantID, clientID)
+	if err != nil {
+		return fmt.Errorf("failed to register client certificate: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		if _, err := db.Exec(`
+			DELETE FROM client_certificates
+			WHERE not_after < now() AND client_id IN (
+				SELECT id FROM clients WHERE tenant_id = $1 AND client_id = $2
+			)
+		`, tenantID, clientID); err != nil {
+			return fmt.Errorf("failed to remove expired client certificates: %w", err)
+		}
+	}
+	return nil
+}
+
+// RotateClientSecret replaces the secret of an existing client credential while keeping
+// the current secret valid as the previous secret until previousExpiresAt. Rotating to the
+// secret already stored is a no-op, so a rotation interrupted after this step can be retried
+// without discarding the previous secret.
+func (db *DB) RotateClientSecret(tenantID, clientID, newSecret string, previousExpiresAt time.Time) error {
+	if len(newSecret) < 8 {
+		return fmt.Errorf("client secret must be at least 8 characters")
+	}
+	if err := db.EnsureSchema(); err != nil {
+		return err
+	}
+
+	var stored string
+	err := db.QueryRow(`
+		SELECT client_secret FROM clients
+		WHERE tenant_id = $1 AND client_id = $2 AND deleted_at IS NULL
+	`, tenantID, clientID).Scan(&stored)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("client '%s' not found", clientID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get client secret: %w", err)
+	}
+	if secretMatches(stored, newSecret) {
+		return nil
+	}
+
+	secretHash, err := hashSecret(db.SecretHash, newSecret)
+	if err != nil {
+		return err
+	}
+	res, err := db.Exec(`
+		UPDATE clients
+		SET previous_client_secret = client_secret, previous_secret_expires_at = $1,
+			client_secret = $2, updated_at = now()
+		WHERE tenant_id = $3 AND client_id = $4 AND client_secret = $5 AND deleted_at IS NULL
+	`, previousExpiresAt, secretHash, tenantID, clientID, stored)
+	if err != nil {
+		return fmt.Errorf("failed to rotate client secret: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("client '%s' changed during the rotation", clientID)
+	}
+	return nil
+}
+
+// RetirePreviousClientSecret invalidates the previous secret of a client credential
+func (db *DB) RetirePreviousClientSecret(tenantID, clientID string) error {
+	if err := db.EnsureSchema(); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`
+		UPDATE clients
+		SET previous_client_secret = NULL, previous_secret_expires_at = NULL, updated_at = now()
+		WHERE tenant_id = $1 AND client_id = $2
+	`, tenantID, clientID); err != nil {
+		return fmt.Errorf("failed to retire previous client secret: %w", err)
+	}
+	return nil
+}
+
+// SetClientExpiry sets when a client credential stops being accepted (nil never expires)
+func (db *DB) SetClientExpiry(tenantID, clientID string, expiresAt *time.Time) error {
+	if err := db.EnsureSchema(); err != nil {
+		return err
+	}
+
+	res, err := db.Exec(`
+		UPDATE clients SET expires_at = $1, updated_at = now()
+		WHERE tenant_id = $2 AND client_id = $3 AND deleted_at IS NULL
+	`, expiresAt, tenantID, clientID)
+	if err != nil {
+		return fmt.Errorf("failed to set client expiry: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("client '%s' not found", clientID)
+	}
+	return nil
+}
+
+// ClientRateLimit caps the request rate of a client
+type ClientRateLimit struct {
+	RequestsPerSecond int
+	Burst             int
+}
+
+// SetClientPolicy stores the network restrictions and rate limit of a client, so gateways
+// can enforce them. Empty allowedCIDRs and a nil rateLimit remove the restrictions.
+func (db *DB) SetClientPolicy(tenantID, clientID string, allowedCIDRs []string, rateLimit *ClientRateLimit) error {
+	if err := db.EnsureSchema(); err != nil {
+		return err
+	}
+
+	var cidrs any
+	if len(allowedCIDRs) > 0 {
+		cidrs = pq.Array(allowedCIDRs)
+	}
+	var rps, burst *int
+	if rateLimit != nil {
+		rps, burst = &rateLimit.RequestsPerSecond, &rateLimit.Burst
+	}
+
+	res, err := db.Exec(`
+		UPDATE clients SET allowed_cidrs = $1::cidr[], rate_limit_rps = $2, rate_limit_burst = $3, updated_at = now()
+		WHERE tenant_id = $4 AND client_id = $5 AND deleted_at IS NULL
+	`, cidrs, rps, burst, tenantID, clientID)
+	if err != nil {
+		return fmt.Errorf("failed to set client policy: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("client '%s' not found", clientID)
+	}
+	return nil
+}
+
+// SetClientSecret replaces the secret of an existing client credential
+func (db *DB) SetClientSecret(tenantID, clientID, clientSecret string) error {
+	if len(clientSecret) < 8 {
+		return fmt.Errorf("client secret must be at least 8 characters")
+	}
+	secretHash, err := hashSecret(db.SecretHash, clientSecret)
+	if err != nil {
+		return err
+	}
+	return db.setClientSecretHash(tenantID, clientID, secretHash)
+}
+
+// setClientSecretHash stores an already hashed client secret
+func (db *DB) setClientSecretHash(tenantID, clientID, secretHash string) error {
+	res, err := db.Exec(`
+		UPDATE clients SET client_secret = $1, updated_at = now()
+		WHERE tenant_id = $2 AND client_id = $3 AND deleted_at IS NULL
+	`, secretHash, tenantID, clientID)
+	if err != nil {
+		return fmt.Errorf("failed to update client secret: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("client '%s' not found", clientID)
+	}
+	return nil
+}
+
+// RevokeClient deletes a client credential and its stream scopes, so the client can no
+// longer authenticate. Revoking a client that does not exist is not an error.
+func (db *DB) RevokeClient(tenantID, clientID string) error {
+	if _, err := db.Exec(`DELETE FROM clients WHERE tenant_id = $1 AND client_id = $2`, tenantID, clientID); err != nil {
+		return fmt.Errorf("failed to revoke client: %w", err)
+	}
+	return nil
+}
+
+// plaintextClientSecrets matches clients whose current or previous secret predates hashing
+const plaintextClientSecrets = `(client_secret !~ '^\$(2[aby]|argon2id)\$'
+	OR (COALESCE(previous_client_secret, '') <> '' AND previous_client_secret !~ '^\$(2[aby]|argon2id)\$'))`
+
+// HashClientSecrets hashes client secrets still stored in plaintext, in batches. progress is
+// called after every batch with the number of clients hashed, failed and found in total, and
+// failure with the client ID and error of every client that could not be hashed.
+// Rows changed concurrently are left to the writer, which stores them hashed already.
+func (db *DB) HashClientSecrets(ctx context.Context, progress func(hashed, failed, total int), failure func(clientID string, err error)) error {
+	if err := db.EnsureSchema(); err != nil {
+		return err
+	}
+
+	var total int
+	if err := db.QueryRowContext(ctx, `SELECT count(*) FROM clients WHERE `+plaintextClientSecrets).Scan(&total); err != nil {
+		return fmt.Errorf("failed to count plaintext client secrets: %w", err)
+	}
+	progress(0, 0, total)
+
+	type row struct {
+		id, clientID, secret, previous string
+	}
+	hashed, failed := 0, 0
+	lastID := "00000000-0000-0000-0000-000000000000"
+	for {
+		rows, err := db.QueryContext(ctx, `
+			SELECT id, client_id, client_secret, COALESCE(previous_client_secret, '') FROM clients
+			WHERE id > $1 AND `+plaintextClientSecrets+`
+			ORDER BY id LIMIT 100
+		`, lastID)
+		if err != nil {
+			return fmt.Errorf("failed to list plaintext client secrets: %w", err)
+		}
+		var batch []row
+		for rows.Next() {
+			var r row
+			if err := rows.Scan(&r.id, &r.clientID, &r.secret, &r.previous); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan client: %w", err)
+			}
+			batch = append(batch, r)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to list plaintext client secrets: %w", err)
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		for _, r := range batch {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			lastID = r.id
+
+			secret, previous := r.secret, r.previous
+			var hashErr error
+			if !isHashedSecret(secret) {
+				secret, hashErr = hashSecret(db.SecretHash, secret)
+			}
+			if hashErr == nil && previous != "" && !isHashedSecret(previous) {
+				previous, hashErr = hashSecret(db.SecretHash, previous)
+			}
+			if hashErr != nil {
+				failed++
+				failure(r.clientID, hashErr)
+				continue
+			}
+
+			if _, err := db.ExecContext(ctx, `
+				UPDATE clients SET client_secret = $1, previous_client_secret = NULLIF($2, ''), updated_at = now()
+				WHERE id = $3 AND client_secret = $4 AND COALESCE(previous_client_secret, '') = $5
+			`, secret, previous, r.id, r.secret, r.previous); err != nil {
+				return fmt.Errorf("failed to hash client secret: %w", err)
+			}
+			hashed++
+		}
+		progress(hashed, failed, total)
+	}
+}
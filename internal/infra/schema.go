@@ -0,0 +1,66 @@
+package infra
+
+import "fmt"
+
+// schemaExtensions are operator-owned tables and columns layered on top of the
+// frkr-common migrations. Every statement must be idempotent, since they are
+// applied on first use by each operator process.
+var schemaExtensions = []string{
+	`CREATE TABLE IF NOT EXISTS user_roles (
+		user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		role VARCHAR(255) NOT NULL,
+		permission VARCHAR(64) NOT NULL,
+		stream VARCHAR(255) NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (user_id, role, permission, stream)
+	)`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS disabled BOOLEAN NOT NULL DEFAULT false`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS lock_reason TEXT`,
+	`CREATE TABLE IF NOT EXISTS user_password_history (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		password_hash VARCHAR(255) NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_user_password_history_user ON user_password_history (user_id, created_at DESC)`,
+	`ALTER TABLE clients ADD COLUMN IF NOT EXISTS previous_client_secret VARCHAR(255)`,
+	`ALTER TABLE clients ADD COLUMN IF NOT EXISTS previous_secret_expires_at TIMESTAMPTZ`,
+	`ALTER TABLE clients ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ`,
+	`CREATE TABLE IF NOT EXISTS client_scopes (
+		client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+		stream_id UUID NOT NULL REFERENCES streams(id) ON DELETE CASCADE,
+		permission VARCHAR(32) NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (client_id, stream_id, permission)
+	)`,
+	`CREATE TABLE IF NOT EXISTS client_certificates (
+		fingerprint VARCHAR(64) PRIMARY KEY,
+		client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+		subject VARCHAR(512) NOT NULL,
+		not_after TIMESTAMPTZ NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_client_certificates_client ON client_certificates (client_id)`,
+	`ALTER TABLE clients ADD COLUMN IF NOT EXISTS allowed_cidrs CIDR[]`,
+	`ALTER TABLE clients ADD COLUMN IF NOT EXISTS rate_limit_rps INTEGER`,
+	`ALTER TABLE clients ADD COLUMN IF NOT EXISTS rate_limit_burst INTEGER`,
+	`ALTER TABLE clients ADD COLUMN IF NOT EXISTS disabled BOOLEAN NOT NULL DEFAULT false`,
+}
+
+// EnsureSchema applies the operator schema extensions once the core tables exist
+func (db *DB) EnsureSchema() error {
+	db.schemaMu.Lock()
+	defer db.schemaMu.Unlock()
+
+	if db.schemaReady {
+		return nil
+	}
+	for _, stmt := range schemaExtensions {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to apply schema extension: %w", err)
+		}
+	}
+	db.schemaReady = true
+	return nil
+}
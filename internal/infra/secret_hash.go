@@ -0,0 +1,109 @@
+package infra
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// SecretHashAlgorithm is the algorithm used to hash client secrets at rest
+type SecretHashAlgorithm string
+
+const (
+	// SecretHashPlaintext stores client secrets as they are, for gateways that compare the
+	// stored secret as plaintext (frkr-common v0.3.3 and earlier)
+	SecretHashPlaintext SecretHashAlgorithm = ""
+	SecretHashBcrypt    SecretHashAlgorithm = "bcrypt"
+	SecretHashArgon2id  SecretHashAlgorithm = "argon2id"
+)
+
+// argon2id parameters, encoded into every hash so they can be raised later
+const (
+	argon2idTime    = 1
+	argon2idMemory  = 64 * 1024
+	argon2idThreads = 4
+	argon2idKeyLen  = 32
+	argon2idSaltLen = 16
+)
+
+// ParseSecretHashAlgorithm validates a hash algorithm name; empty selects bcrypt
+func ParseSecretHashAlgorithm(name string) (SecretHashAlgorithm, error) {
+	switch alg := SecretHashAlgorithm(strings.ToLower(name)); alg {
+	case "":
+		return SecretHashBcrypt, nil
+	case SecretHashBcrypt, SecretHashArgon2id:
+		return alg, nil
+	default:
+		return "", fmt.Errorf("unsupported secret hash algorithm %q (expected bcrypt or argon2id)", name)
+	}
+}
+
+// hashSecret hashes a secret with the given algorithm; SecretHashPlaintext returns it as is
+func hashSecret(alg SecretHashAlgorithm, secret string) (string, error) {
+	switch alg {
+	case SecretHashPlaintext:
+		return secret, nil
+	case SecretHashBcrypt:
+		if len(secret) > 72 {
+			return "", fmt.Errorf("client secret must be at most 72 bytes with bcrypt; use argon2id for longer secrets")
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash client secret: %w", err)
+		}
+		return string(hash), nil
+	case SecretHashArgon2id:
+		salt := make([]byte, argon2idSaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return "", fmt.Errorf("failed to generate salt: %w", err)
+		}
+		key := argon2.IDKey([]byte(secret), salt, argon2idTime, argon2idMemory, argon2idThreads, argon2idKeyLen)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, argon2idMemory, argon2idTime, argon2idThreads,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	default:
+		return "", fmt.Errorf("unsupported secret hash algorithm %q", alg)
+	}
+}
+
+// isHashedSecret reports whether a stored secret is a hash rather than legacy plaintext
+func isHashedSecret(stored string) bool {
+	return strings.HasPrefix(stored, "$2a$") || strings.HasPrefix(stored, "$2b$") ||
+		strings.HasPrefix(stored, "$2y$") || strings.HasPrefix(stored, "$argon2id$")
+}
+
+// secretMatches reports whether secret matches a stored hash or legacy plaintext secret
+func secretMatches(stored, secret string) bool {
+	switch {
+	case strings.HasPrefix(stored, "$argon2id$"):
+		var version, memory, time, threads int
+		parts := strings.Split(stored, "$")
+		if len(parts) != 6 {
+			return false
+		}
+		if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+			return false
+		}
+		if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+			return false
+		}
+		salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+		if err != nil {
+			return false
+		}
+		key, err := base64.RawStdEncoding.DecodeString(parts[5])
+		if err != nil {
+			return false
+		}
+		candidate := argon2.IDKey([]byte(secret), salt, uint32(time), uint32(memory), uint8(threads), uint32(len(key)))
+		return subtle.ConstantTimeCompare(candidate, key) == 1
+	case isHashedSecret(stored):
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(secret)) == nil
+	default:
+		return subtle.ConstantTimeCompare([]byte(stored), []byte(secret)) == 1
+	}
+}
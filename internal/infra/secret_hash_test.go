@@ -0,0 +1,127 @@
+package infra
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHashSecret_RoundTrip(t *testing.T) {
+	for _, alg := range []SecretHashAlgorithm{SecretHashBcrypt, SecretHashArgon2id} {
+		t.Run(string(alg), func(t *testing.T) {
+			hash, err := hashSecret(alg, "correct-horse")
+			if err != nil {
+				t.Fatalf("hashSecret: %v", err)
+			}
+			if !isHashedSecret(hash) {
+				t.Errorf("isHashedSecret(%q) = false, want true", hash)
+			}
+			if !secretMatches(hash, "correct-horse") {
+				t.Errorf("secretMatches with the hashed secret = false, want true")
+			}
+			if secretMatches(hash, "wrong-horse") {
+				t.Errorf("secretMatches with another secret = true, want false")
+			}
+		})
+	}
+}
+
+func TestHashSecret_Plaintext(t *testing.T) {
+	stored, err := hashSecret(SecretHashPlaintext, "correct-horse")
+	if err != nil {
+		t.Fatalf("hashSecret: %v", err)
+	}
+	if stored != "correct-horse" {
+		t.Errorf("hashSecret without an algorithm = %q, want the secret unchanged", stored)
+	}
+	if isHashedSecret(stored) {
+		t.Errorf("isHashedSecret(%q) = true, want false", stored)
+	}
+	if _, err := hashSecret(SecretHashPlaintext, strings.Repeat("a", 100)); err != nil {
+		t.Errorf("hashSecret of a long plaintext secret: %v", err)
+	}
+}
+
+func TestHashSecret_Salted(t *testing.T) {
+	a, err := hashSecret(SecretHashArgon2id, "correct-horse")
+	if err != nil {
+		t.Fatalf("hashSecret: %v", err)
+	}
+	b, err := hashSecret(SecretHashArgon2id, "correct-horse")
+	if err != nil {
+		t.Fatalf("hashSecret: %v", err)
+	}
+	if a == b {
+		t.Errorf("two hashes of the same secret are equal: %q", a)
+	}
+}
+
+func TestHashSecret_BcryptLimit(t *testing.T) {
+	if _, err := hashSecret(SecretHashBcrypt, strings.Repeat("a", 72)); err != nil {
+		t.Errorf("hashSecret with 72 bytes: %v", err)
+	}
+	if _, err := hashSecret(SecretHashBcrypt, strings.Repeat("a", 73)); err == nil {
+		t.Errorf("hashSecret with 73 bytes succeeded, want an error")
+	}
+
+	// argon2id has no such limit, and bytes past 72 still count
+	long := strings.Repeat("a", 100)
+	hash, err := hashSecret(SecretHashArgon2id, long)
+	if err != nil {
+		t.Fatalf("hashSecret with argon2id: %v", err)
+	}
+	if secretMatches(hash, long[:72]) {
+		t.Errorf("secretMatches with a truncated secret = true, want false")
+	}
+}
+
+func TestHashSecret_UnsupportedAlgorithm(t *testing.T) {
+	if _, err := hashSecret("md5", "correct-horse"); err == nil {
+		t.Errorf("hashSecret with md5 succeeded, want an error")
+	}
+}
+
+func TestSecretMatches_LegacyPlaintext(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored string
+		secret string
+		want   bool
+	}{
+		{name: "equal", stored: "legacy-secret", secret: "legacy-secret", want: true},
+		{name: "different", stored: "legacy-secret", secret: "other-secret", want: false},
+		{name: "prefix", stored: "legacy-secret", secret: "legacy", want: false},
+		{name: "empty stored", stored: "", secret: "legacy-secret", want: false},
+		{name: "malformed argon2id", stored: "$argon2id$v=19$broken", secret: "legacy-secret", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := secretMatches(tt.stored, tt.secret); got != tt.want {
+				t.Errorf("secretMatches(%q, %q) = %v, want %v", tt.stored, tt.secret, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsHashedSecret(t *testing.T) {
+	tests := []struct {
+		stored string
+		want   bool
+	}{
+		{stored: "$2a$10$abcdefghijklmnopqrstuv", want: true},
+		{stored: "$2b$10$abcdefghijklmnopqrstuv", want: true},
+		{stored: "$2y$10$abcdefghijklmnopqrstuv", want: true},
+		{stored: "$argon2id$v=19$m=65536,t=1,p=4$c2FsdA$a2V5", want: true},
+		{stored: "$argon2i$v=19$m=65536,t=1,p=4$c2FsdA$a2V5", want: false},
+		{stored: "plaintext-secret", want: false},
+		{stored: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stored, func(t *testing.T) {
+			if got := isHashedSecret(tt.stored); got != tt.want {
+				t.Errorf("isHashedSecret(%q) = %v, want %v", tt.stored, got, tt.want)
+			}
+		})
+	}
+}
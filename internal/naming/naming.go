@@ -0,0 +1,70 @@
+// Package naming derives Kubernetes object names for frkr resources.
+package naming
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// maxNameLength is the maximum length of a DNS-1123 subdomain
+const maxNameLength = 253
+
+// hashLength is the number of hex digits of the hashes added to names
+const hashLength = 8
+
+// DNSName joins the parts with dashes and turns the result into a valid
+// DNS-1123 subdomain: lowercased, with unsupported characters replaced by
+// dashes. Names that are too long are truncated and suffixed with a hash of
+// the full name so they stay unique.
+func DNSName(parts ...string) string {
+	joined := strings.ToLower(strings.Join(parts, "-"))
+
+	var b strings.Builder
+	for _, r := range joined {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '.' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('-')
+		}
+	}
+	name := strings.Trim(b.String(), "-.")
+
+	if len(name) > maxNameLength {
+		sum := sha256.Sum256([]byte(joined))
+		suffix := hex.EncodeToString(sum[:])[:hashLength]
+		name = strings.TrimRight(name[:maxNameLength-len(suffix)-1], "-.") + "-" + suffix
+	}
+	return name
+}
+
+// PartsHash returns a short hash of the parts as a sequence. Each part is length-prefixed,
+// so parts that join to the same text (such as "team-a", "admin" and "team", "a-admin")
+// hash differently.
+func PartsHash(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(strconv.Itoa(len(p)) + ":" + p))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:hashLength]
+}
+
+// UniqueDNSName is DNSName with PartsHash appended, so different parts never share a name
+// even when joining and sanitizing them gives the same text
+func UniqueDNSName(parts ...string) string {
+	suffix := PartsHash(parts...)
+	name := DNSName(parts...)
+	if len(name) > maxNameLength-len(suffix)-1 {
+		name = strings.TrimRight(name[:maxNameLength-len(suffix)-1], "-.")
+	}
+	if name == "" {
+		return suffix
+	}
+	return name + "-" + suffix
+}
+
+// UserResourceName is the FrkrUser name used for a user of a tenant
+func UserResourceName(tenantID, username string) string {
+	return UniqueDNSName(tenantID, username)
+}
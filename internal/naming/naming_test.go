@@ -0,0 +1,60 @@
+package naming
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDNSName(t *testing.T) {
+	tests := []struct {
+		name  string
+		parts []string
+		want  string
+	}{
+		{name: "simple", parts: []string{"tenant-1", "admin"}, want: "tenant-1-admin"},
+		{name: "lowercased", parts: []string{"Acme", "Admin"}, want: "acme-admin"},
+		{name: "invalid characters replaced", parts: []string{"acme corp", "jane_doe@example.com"}, want: "acme-corp-jane-doe-example.com"},
+		{name: "trimmed", parts: []string{"_acme", "admin_"}, want: "acme-admin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DNSName(tt.parts...); got != tt.want {
+				t.Errorf("DNSName(%q) = %q, want %q", tt.parts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDNSName_Truncates(t *testing.T) {
+	a := DNSName("tenant", strings.Repeat("a", 300))
+	b := DNSName("tenant", strings.Repeat("a", 299)+"b")
+	if len(a) > maxNameLength || len(b) > maxNameLength {
+		t.Fatalf("names exceed %d characters: %d, %d", maxNameLength, len(a), len(b))
+	}
+	if a == b {
+		t.Errorf("truncated names of different inputs collide: %q", a)
+	}
+}
+
+func TestUniqueDNSName(t *testing.T) {
+	a := UniqueDNSName("team-a", "admin")
+	b := UniqueDNSName("team", "a-admin")
+	if a == b {
+		t.Errorf("ambiguous parts share the name %q", a)
+	}
+	if !strings.HasPrefix(a, "team-a-admin-") || len(a) != len("team-a-admin-")+hashLength {
+		t.Errorf("UniqueDNSName() = %q, want team-a-admin-<hash>", a)
+	}
+	if UniqueDNSName("Acme", "admin") == UniqueDNSName("acme", "admin") {
+		t.Error("parts differing in case share a name")
+	}
+	if a != UniqueDNSName("team-a", "admin") {
+		t.Error("UniqueDNSName() is not stable")
+	}
+
+	long := UniqueDNSName("tenant", strings.Repeat("a", 300))
+	if len(long) > maxNameLength || !strings.HasSuffix(long, PartsHash("tenant", strings.Repeat("a", 300))) {
+		t.Errorf("long name %q is not truncated before the hash", long)
+	}
+}